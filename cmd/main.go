@@ -7,8 +7,9 @@ import (
 
 func main() {
 	App := app.NewApp("tool", "tool",
-		app.WithDescription("tool create by lwm"),
-		app.WithNoConfig(),
+		app.WithDescription("cmd.description"),
+		app.WithPlugins(),
+		app.WithMiddleware(app.Recover(), app.Timing()),
 		app.WithCommands(cmd.NewUpdateCommand(), cmd.NewPdf2DocxCommand(), cmd.NewInitCommand(), cmd.NewDownloadCommand()),
 	)
 	App.Run()
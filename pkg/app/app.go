@@ -1,21 +1,28 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/lwm-galactic/utool/pkg/log"
 
-	"github.com/lwm-galactic/tools/term"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 var (
 	progressMessage = color.GreenString("==>")
 )
 
+// globalFlagSetName is the flag section inherited by all subcommands.
+const globalFlagSetName = "global"
+
 // App is model for command-line-application.
 type App struct {
 	// commandName: cli executable file name.
@@ -27,13 +34,35 @@ type App struct {
 	// options: app configuration items
 	options CliOptions
 	// runFunc:  cli entrance func .
-	runFunc RunFunc
+	runFunc RunContextFunc
+	// middlewares: wrap the run func of the app and of all its commands.
+	middlewares []Middleware
 	// silence: -true log will not stdout recommend deploy set.
 	silence bool
 	// noConfig: -true --config flag will not be use you can not configuration by file.
 	noConfig bool
 	// commands: subcommands.
 	commands []*Command
+	// config: configuration state of the app, nil when noConfig is set.
+	config *config
+	// printConfig: format of --print-config, empty when the options are not printed.
+	printConfig string
+	// output: format of the command results given by --output.
+	output string
+	// restoreLog: restores the output of the logs redirected by checkOutput.
+	restoreLog func()
+	// dryRun: -true external commands are printed instead of being run.
+	dryRun bool
+	// plugins: -true external <commandName>-<name> executables are run as subcommands.
+	plugins bool
+	// lang: language of the messages given by --lang.
+	lang string
+	// printer: language of the messages of the current run, see localize.
+	printer *i18n.Printer
+	// texts: texts of the command tree translated by localize by location, see setText.
+	texts map[*string]message
+	// helps: flag sections and args shown by the help of the commands of the tree.
+	helps map[*cobra.Command]commandHelp
 
 	args cobra.PositionalArgs
 	cmd  *cobra.Command
@@ -42,6 +71,20 @@ type App struct {
 // RunFunc defines the application's startup callback function.
 type RunFunc func(option CliOptions) error
 
+// RunContextFunc defines the application's startup callback function with a
+// context, the context is canceled when the process receives SIGINT or SIGTERM.
+type RunContextFunc func(ctx context.Context, option CliOptions) error
+
+// withContext adapts a RunFunc to a RunContextFunc which ignores the context.
+func (run RunFunc) withContext() RunContextFunc {
+	if run == nil {
+		return nil
+	}
+	return func(_ context.Context, option CliOptions) error {
+		return run(option)
+	}
+}
+
 // Option defines optional parameters for initializing the application structure.
 type Option func(*App)
 
@@ -53,6 +96,13 @@ func WithOptions(opt CliOptions) Option {
 
 // WithRunFunc is used to set the application startup function option.
 func WithRunFunc(run RunFunc) Option {
+	return func(a *App) {
+		a.runFunc = run.withContext()
+	}
+}
+
+// WithRunContextFunc is used to set the context aware application startup function option.
+func WithRunContextFunc(run RunContextFunc) Option {
 	return func(a *App) {
 		a.runFunc = run
 	}
@@ -100,7 +150,7 @@ func WithDefaultValidArgs() Option {
 		a.args = func(cmd *cobra.Command, args []string) error {
 			for _, arg := range args {
 				if len(arg) > 0 {
-					return fmt.Errorf("%q does not take any arguments, got %q", cmd.CommandPath(), args)
+					return i18n.Errorf("app.args.none", cmd.CommandPath(), args)
 				}
 			}
 
@@ -115,6 +165,7 @@ func NewApp(name string, commandName string, opts ...Option) *App {
 	a := &App{
 		name:        name,
 		commandName: commandName,
+		printer:     i18n.NewPrinter(i18n.Language()),
 	}
 
 	for _, o := range opts {
@@ -130,145 +181,263 @@ func (a *App) Build() {
 }
 
 func (a *App) buildCommand() {
+	a.helps = map[*cobra.Command]commandHelp{}
+	a.texts = map[*string]message{}
 	cmd := cobra.Command{
 		Use:           FormatBaseName(a.name),
 		Short:         a.name,
-		Long:          a.description,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Args:          a.args,
+		Annotations:   map[string]string{annotationOptions: "true"},
 	}
 
+	a.setText(&cmd.Long, a.description)
+
 	cmd.SetOut(os.Stdout)
 	cmd.SetErr(os.Stderr)
+	cmd.SetFlagErrorFunc(a.flagErrorFunc)
 	cmd.Flags().SortFlags = true
 
 	// init flags.
 	cli.InitFlags(cmd.Flags())
 
+	var namedFlagSets cli.NamedFlagSets
+	if a.options != nil {
+		namedFlagSets = optionFlags(a.options, a.commandName)
+	}
+
+	a.addPrintConfigFlag(namedFlagSets.FlagSet(globalFlagSetName))
+	a.addOutputFlag(namedFlagSets.FlagSet(globalFlagSetName))
+	a.addDryRunFlag(namedFlagSets.FlagSet(globalFlagSetName))
+	a.addLangFlag(namedFlagSets.FlagSet(globalFlagSetName))
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := a.checkLanguage(cmd, args); err != nil {
+			return err
+		}
+		if err := a.checkPrintConfig(cmd, args); err != nil {
+			return err
+		}
+		return a.checkOutput(cmd, args)
+	}
+
+	// add config flag
+	a.config = nil
+	if !a.noConfig {
+		a.config = newConfig(a.commandName)
+		a.config.addConfigFlag(namedFlagSets.FlagSet(globalFlagSetName))
+		a.setFlagUsage(namedFlagSets.FlagSet(globalFlagSetName), configFlagName, "app.flag.config")
+	}
+	// cli.AddGlobalFlags(namedFlagSets.FlagSet("global"), cmd.Name())
+	inherited := addFlagSections(&cmd, namedFlagSets)
+	if err := registerCompletions(&cmd, namedFlagSets, a.options); err != nil {
+		log.Warnf("failed to register completions of %s: %v", a.name, err)
+	}
+
 	// setting subcommand
 	if len(a.commands) > 0 {
 		for _, command := range a.commands {
-			cmd.AddCommand(command.cobraCommand())
+			cmd.AddCommand(command.cobraCommand(a, inherited, a.middlewares))
 		}
-		// to add app help flag to app.
-		// cmd.SetHelpCommand(helpCommand(FormatBaseName(a.commandName)))
-		cmd.AddCommand(addListCmd())
+		a.addCommandGroups(&cmd)
 	}
-
-	if a.runFunc != nil {
-		cmd.RunE = a.runCommand
+	if len(a.commands) > 0 || a.plugins {
+		cmd.AddCommand(a.addListCmd())
+	}
+	if a.plugins {
+		cmd.AddCommand(a.addPluginCmd())
 	}
 
-	var namedFlagSets cli.NamedFlagSets
-	if a.options != nil {
-		namedFlagSets = a.options.Flags()
+	if a.config != nil {
+		cmd.AddCommand(a.addConfigCmd())
 	}
+	cmd.CompletionOptions.DisableDefaultCmd = true
+	cmd.AddCommand(a.addCompletionCmd(cmd.Name()), a.addVersionCmd(), a.addDocsCmd())
+	addVersionFlag(&cmd)
 
-	// add config flag
-	if !a.noConfig {
-		addConfigFlag(a.commandName, namedFlagSets.FlagSet("global"))
+	if a.runFunc != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return a.runCommand(cmd, args, namedFlagSets)
+		}
 	}
-	// cli.AddGlobalFlags(namedFlagSets.FlagSet("global"), cmd.Name())
-	cmd.Flags().AddFlagSet(namedFlagSets.FlagSet("global"))
 
-	addCmdTemplate(&cmd, namedFlagSets)
+	// to add app help flag and help command to app.
+	a.addHelpFlag(cmd.Name(), cmd.Flags())
+	cmd.SetHelpCommand(a.helpCommand(cmd.Name()))
+	// cobra adds the help command on the first run, it is added now so that
+	// localize translates it like the other commands.
+	cmd.InitDefaultHelpCmd()
+	a.addCmdTemplate(&cmd, namedFlagSets)
+	a.recordFlagTexts(&cmd)
 	a.cmd = &cmd
 }
 
-// Run is used to launch the application.
+// Command returns the cobra command tree of the application.
+func (a *App) Command() *cobra.Command {
+	return a.cmd
+}
+
+// Run is used to launch the application with the arguments of the process and
+// exit with the resulting code. SIGINT and SIGTERM cancel the context passed to
+// the run functions, a second signal during the grace period of the commands
+// stops the process at once.
 func (a *App) Run() {
-	if err := a.cmd.Execute(); err != nil {
-		fmt.Printf("%v %v\n", color.RedString("Error:"), err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// restore the default behavior of the signals as soon as the first one is received.
+		<-ctx.Done()
+		stop()
+	}()
+	code := a.RunWithArgs(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// RunWithArgs runs the application with the given arguments and returns the
+// exit code instead of exiting, it is the single place where errors are
+// reported to the user and mapped to exit codes. An app can be run more than
+// once, the flags of the tree and the options they set are reset to their
+// defaults before every run, and the commands get the context of the run. The
+// context carries the printer of the language of the run, see i18n.FromContext.
+func (a *App) RunWithArgs(ctx context.Context, args []string) int {
+	defer log.Flush()
+	defer func() {
+		if a.restoreLog != nil {
+			a.restoreLog()
+			a.restoreLog = nil
+		}
+	}()
+
+	resetCommand(a.cmd)
+	a.localize(args)
+	if a.config != nil {
+		a.config.reset()
+	}
+	if p, ok := a.findPlugin(args); ok {
+		return a.runPlugin(ctx, p, args[1:])
+	}
+
+	if args == nil {
+		args = []string{}
+	}
+	a.cmd.SetArgs(args)
+	err := a.cmd.ExecuteContext(i18n.NewContext(ctx, a.printer))
+	if err == nil {
+		return ExitCodeOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Err == nil {
+		return exitErr.Code
+	}
+
+	fmt.Fprintf(a.cmd.ErrOrStderr(), "%v %v\n", color.RedString(a.printer.T("app.error")), a.printer.Localize(err))
+	if hint := exitHint(err); hint != "" {
+		fmt.Fprintf(a.cmd.ErrOrStderr(), "%v %s\n", color.YellowString(a.printer.T("app.hint")), hint)
+	}
+
+	return ExitCode(err)
+}
+
+// resetCommand resets the flags of cmd and of its subcommands, see
+// cli.ResetFlags, and drops their context, cobra only passes the context of a
+// run to the commands which do not have one yet.
+func resetCommand(cmd *cobra.Command) {
+	cli.ResetFlags(cmd.PersistentFlags())
+	cli.ResetFlags(cmd.Flags())
+	cmd.SetContext(nil)
+	for _, sub := range cmd.Commands() {
+		resetCommand(sub)
 	}
 }
 
 // to run app.
-func (a *App) runCommand(cmd *cobra.Command, args []string) error {
-	printWorkingDir()
+func (a *App) runCommand(cmd *cobra.Command, args []string, fss cli.NamedFlagSets) error {
 	cli.InitFlags(cmd.Flags())
-	if !a.noConfig {
-		if err := viper.BindPFlags(cmd.Flags()); err != nil {
-			return err
-		}
-
-		if err := viper.Unmarshal(a.options); err != nil {
-			return err
-		}
+	v, err := a.resolveOptions(cmd, nil, a.options)
+	if err != nil {
+		return err
 	}
+	if a.printConfig != "" {
+		return a.printOptionSources(cmd, nil, v, a.options)
+	}
+
+	a.printWorkingDir()
 	if !a.silence {
-		log.Infof("%v Starting %s ...", progressMessage, a.name)
+		log.Infof("%v %s", progressMessage, a.printer.T("app.log.starting", a.name))
 
-		if !a.noConfig {
-			log.Infof("%v Config file used: `%s`", progressMessage, viper.ConfigFileUsed())
+		if a.config != nil {
+			log.Infof("%v %s", progressMessage, a.printer.T("app.log.config_file", a.config.fileUsed()))
 		}
 	}
-	if a.options != nil {
-		if err := a.applyOptionRules(); err != nil {
-			return err
-		}
+	if err := a.checkFlagGroups(cmd, nil, fss, nil, args); err != nil {
+		return err
+	}
+	if err := bindArgs(a.options, args); err != nil {
+		return err
+	}
+	if err := a.applyOptionRules(a.options); err != nil {
+		return err
 	}
 	// run application
 	if a.runFunc != nil {
-		return a.runFunc(a.options)
+		return a.runWithPlan(cmd, args, a.runFunc, a.options, a.middlewares)
 	}
 	return nil
 }
 
-func (a *App) applyOptionRules() error {
-	if completableOptions, ok := a.options.(CompletableOptions); ok {
-		if err := completableOptions.Complete(); err != nil {
-			return err
+// resolveOptions unmarshals into options the flags of cmd, the environment and
+// the configuration of the command at path. Every run owns its viper, the
+// command sections of the app configuration are merged into it.
+func (a *App) resolveOptions(cmd *cobra.Command, path []string, options CliOptions) (*viper.Viper, error) {
+	v := viper.New()
+	if a.config != nil {
+		if err := a.config.load(); err != nil {
+			return nil, err
+		}
+		var err error
+		if v, err = a.config.commandViper(path); err != nil {
+			return nil, err
 		}
 	}
-
-	if errs := a.options.Validate(); len(errs) != 0 {
-		return fmt.Errorf("invalid options: %v", errs)
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
 	}
+	bindFlagEnvs(v, cmd.Flags())
 
-	if printableOptions, ok := a.options.(PrintableOptions); ok && !a.silence {
-		log.Infof("%v Config: `%s`", progressMessage, printableOptions.String())
+	if options != nil {
+		if err := v.Unmarshal(options, decodeHook(a.printer)); err != nil {
+			return nil, &InvalidOptionsError{Errors: decodeErrors(err)}
+		}
 	}
 
-	return nil
+	return v, nil
 }
 
-// terminal beautify
-func addCmdTemplate(cmd *cobra.Command, namedFlagSets cli.NamedFlagSets) {
-	usageFmt := "Usage:\n  %s\n"
-	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
-	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
-		fmt.Fprintf(cmd.OutOrStderr(), usageFmt, cmd.UseLine())
-		cli.PrintSections(cmd.OutOrStderr(), namedFlagSets, cols)
-
-		return nil
-	})
-	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
-		fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n"+usageFmt, cmd.Long, cmd.UseLine())
-		cli.PrintSections(cmd.OutOrStdout(), namedFlagSets, cols)
-	})
-
-}
-
-func addListCmd() *cobra.Command {
-	// 创建 list 子命令
-	return &cobra.Command{
-		Use:   "list",
-		Short: "List all available commands",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Available commands:")
-			for _, c := range cmd.Root().Commands() {
-				fmt.Printf(" - %s: %s\n", c.Name(), c.Short)
-			}
-			fmt.Println("To Use tool subcommands --help to show how subcommand use")
-			return nil
-		},
+// addFlagSections registers every section of fss on cmd so that the flags are
+// parsed by cobra. The global section is registered as persistent flags and
+// returned, merged with the sections cmd inherits from its parent, to be passed
+// down to the subcommands.
+func addFlagSections(cmd *cobra.Command, fss cli.NamedFlagSets, parents ...cli.NamedFlagSets) cli.NamedFlagSets {
+	var inherited cli.NamedFlagSets
+	for _, name := range fss.Order {
+		if name == globalFlagSetName {
+			cmd.PersistentFlags().AddFlagSet(fss.FlagSets[name])
+			continue
+		}
+		cmd.Flags().AddFlagSet(fss.FlagSets[name])
+	}
+	if global, ok := fss.FlagSets[globalFlagSetName]; ok {
+		inherited.FlagSet(globalFlagSetName).AddFlagSet(global)
 	}
+	for _, parent := range parents {
+		inherited.Merge(parent)
+	}
+
+	return inherited
 }
 
 // to show working dir
-func printWorkingDir() {
+func (a *App) printWorkingDir() {
 	wd, _ := os.Getwd()
-	log.Infof("%v WorkingDir: %s", progressMessage, wd)
+	log.Infof("%v %s", progressMessage, a.printer.T("app.log.working_dir", wd))
 }
@@ -0,0 +1,286 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type testOptions struct {
+	Verbose bool
+	Name    string
+}
+
+func (o *testOptions) Flags() (fss cli.NamedFlagSets) {
+	fss.FlagSet("global").BoolVar(&o.Verbose, "verbose", o.Verbose, "Verbose output.")
+	fss.FlagSet("test").StringVar(&o.Name, "name", o.Name, "Name to use.")
+	return fss
+}
+
+func (o *testOptions) Validate() []error { return nil }
+
+type subOptions struct {
+	Name string
+}
+
+func (o *subOptions) Flags() (fss cli.NamedFlagSets) {
+	fss.FlagSet("sub").StringVar(&o.Name, "name", o.Name, "Name to use.")
+	return fss
+}
+
+func (o *subOptions) Validate() []error { return nil }
+
+func Test_InheritedFlags(t *testing.T) {
+	rootOpts, subOpts := &testOptions{}, &subOptions{}
+	sub := app.NewCommand("sub", "sub command", app.WithCommandOptions(subOpts))
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithOptions(rootOpts), app.WithCommands(sub))
+
+	c, rest, err := a.Command().Find([]string{"sub", "--name", "foo", "--verbose"})
+	assert.Nil(t, err)
+	assert.Nil(t, c.ParseFlags(rest))
+	assert.Equal(t, "foo", subOpts.Name)
+	assert.True(t, rootOpts.Verbose)
+	assert.Equal(t, "", rootOpts.Name)
+}
+
+func Test_HelpSections(t *testing.T) {
+	sub := app.NewCommand("sub", "sub command", app.WithCommandOptions(&subOptions{}))
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithOptions(&testOptions{}), app.WithCommands(sub))
+
+	c, _, err := a.Command().Find([]string{"sub"})
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	c.SetOut(&buf)
+	assert.Nil(t, c.Help())
+	assert.Contains(t, buf.String(), "Sub flags:")
+	assert.Contains(t, buf.String(), "Global flags:")
+	assert.Contains(t, buf.String(), "--verbose")
+	assert.Contains(t, buf.String(), "--name")
+}
+
+type invalidOptions struct{}
+
+func (o *invalidOptions) Flags() (fss cli.NamedFlagSets) { return fss }
+
+func (o *invalidOptions) Validate() []error {
+	return []error{errors.New("url or file is required"), nil, errors.New("output directory does not exist")}
+}
+
+func Test_CommandValidate(t *testing.T) {
+	called := false
+	sub := app.NewCommand("sub", "sub command",
+		app.WithCommandOptions(&invalidOptions{}),
+		app.WithCommandRunFunc(func(option app.CliOptions) error {
+			called = true
+			return nil
+		}),
+	)
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(sub))
+	a.Command().SetArgs([]string{"sub"})
+
+	err := a.Command().Execute()
+	var invalid *app.InvalidOptionsError
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Len(t, invalid.Errors, 2)
+	}
+	assert.Equal(t, "invalid options:\n  - url or file is required\n  - output directory does not exist", err.Error())
+	assert.False(t, called)
+}
+
+func Test_RunContextFunc(t *testing.T) {
+	type ctxKey struct{}
+	var got interface{}
+	sub := app.NewCommand("sub", "sub command",
+		app.WithCommandRunContextFunc(func(ctx context.Context, option app.CliOptions) error {
+			got = ctx.Value(ctxKey{})
+			return nil
+		}),
+	)
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(sub))
+	a.Command().SetArgs([]string{"sub"})
+
+	assert.Nil(t, a.Command().ExecuteContext(context.WithValue(context.Background(), ctxKey{}, "value")))
+	assert.Equal(t, "value", got)
+}
+
+func Test_RunWithArgs(t *testing.T) {
+	failing := app.NewCommand("fail", "failing command",
+		app.WithCommandRunFunc(func(option app.CliOptions) error {
+			return app.NewExitError(3, "install the dependency first", errors.New("dependency missing"))
+		}),
+	)
+	canceled := app.NewCommand("cancel", "canceled command",
+		app.WithCommandRunContextFunc(func(ctx context.Context, option app.CliOptions) error {
+			return ctx.Err()
+		}),
+	)
+	invalid := app.NewCommand("invalid", "invalid command",
+		app.WithCommandOptions(&invalidOptions{}),
+		app.WithCommandRunFunc(func(option app.CliOptions) error { return nil }),
+	)
+
+	tests := []struct {
+		name   string
+		ctx    func() context.Context
+		args   []string
+		code   int
+		output string
+	}{
+		{name: "ok", args: []string{"list"}, code: app.ExitCodeOK},
+		{name: "exit error", args: []string{"fail"}, code: 3, output: "dependency missing\nHint: install the dependency first\n"},
+		{name: "invalid options", args: []string{"invalid"}, code: app.ExitCodeInvalidOptions, output: "url or file is required"},
+		{name: "unknown flag", args: []string{"fail", "--unknown"}, code: app.ExitCodeInvalidOptions, output: "Run 'test fail --help' for usage."},
+		{
+			name: "canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			args: []string{"cancel"},
+			code: app.ExitCodeInterrupted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(failing, canceled, invalid))
+			var stdout, stderr bytes.Buffer
+			a.Command().SetOut(&stdout)
+			a.Command().SetErr(&stderr)
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+			assert.Equal(t, tt.code, a.RunWithArgs(ctx, tt.args))
+			assert.Contains(t, stderr.String(), tt.output)
+		})
+	}
+}
+
+func Test_SubCommands(t *testing.T) {
+	var called string
+	leaf := func(name string) *app.Command {
+		return app.NewCommand(name, name+" command", app.WithCommandRunFunc(func(option app.CliOptions) error {
+			called = name
+			return nil
+		}))
+	}
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("pdf2docx", "convert pdf files", app.WithSubCommands(leaf("convert"), leaf("gui"))),
+	))
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"pdf2docx", "gui"}))
+	assert.Equal(t, "gui", called)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"pdf2docx", "--help"}))
+	assert.Contains(t, stdout.String(), "Available Commands:\n  convert     convert command\n  gui         gui command\n")
+
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"list"}))
+	assert.Contains(t, stdout.String(), "├── pdf2docx: convert pdf files\n│   ├── convert: convert command\n│   └── gui: gui command\n")
+
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"list", "--json"}))
+	var tree []struct {
+		Name     string `json:"name"`
+		Origin   string `json:"origin"`
+		Commands []struct {
+			Name string `json:"name"`
+		} `json:"commands"`
+	}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &tree))
+	for _, node := range tree {
+		if node.Name == "pdf2docx" {
+			assert.Equal(t, "built-in", node.Origin)
+			assert.Len(t, node.Commands, 2)
+			assert.Equal(t, "gui", node.Commands[1].Name)
+		}
+	}
+}
+
+func Test_CommandMetadata(t *testing.T) {
+	run := app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("download", "download videos", run,
+			app.WithAliases("dl"),
+			app.WithLong("Download videos with you-get."),
+			app.WithExamples("test download --url URL", "test download --file urls.txt")),
+		app.NewCommand("old", "old command", run, app.WithDeprecated("use download instead")),
+		app.NewCommand("secret", "secret command", run, app.WithHidden()),
+		app.NewCommand("beta", "beta command", run, app.WithExperimental(), app.WithGroup("Labs")),
+	))
+	var stdout, stderr bytes.Buffer
+	a.Command().SetOut(&stdout)
+	a.Command().SetErr(&stderr)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"dl", "--help"}))
+	assert.Contains(t, stdout.String(), "Download videos with you-get.\n")
+	assert.Contains(t, stdout.String(), "Aliases:\n  download, dl\n")
+	assert.Contains(t, stdout.String(), "Examples:\n  test download --url URL\n  test download --file urls.txt\n")
+
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"--help"}))
+	assert.Contains(t, stdout.String(), "\nLabs:\n  beta        beta command (experimental)\n")
+	assert.NotContains(t, stdout.String(), "secret")
+	assert.NotContains(t, stdout.String(), "old command")
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"old"}))
+	assert.Equal(t, "Command \"old\" is deprecated, use download instead\n", stderr.String())
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"secret"}))
+
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"list"}))
+	assert.Contains(t, stdout.String(), "├── download (aliases: dl): download videos\n")
+	assert.Contains(t, stdout.String(), "├── beta [Labs] [experimental]: beta command\n")
+	assert.Contains(t, stdout.String(), "├── old [deprecated: use download instead]: old command\n")
+	assert.NotContains(t, stdout.String(), "secret")
+}
+
+type runOptions struct {
+	OutputDir string   `mapstructure:"output-dir" usage:"Output directory." default:"."`
+	Tags      []string `flag:"tag" usage:"Tags."`
+}
+
+func (o *runOptions) Validate() []error { return nil }
+
+func Test_RunWithArgsTwice(t *testing.T) {
+	type run struct {
+		options runOptions
+		dryRun  bool
+		ctx     string
+	}
+	type ctxKey struct{}
+	var got run
+	opts := &runOptions{}
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("run", "run command",
+			app.WithCommandOptions(opts),
+			app.WithCommandRunContextFunc(func(ctx context.Context, option app.CliOptions) error {
+				value, _ := ctx.Value(ctxKey{}).(string)
+				got = run{options: *opts, dryRun: app.IsDryRun(ctx), ctx: value}
+				return nil
+			}),
+		),
+	))
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"run", "--output-dir", "out", "--tag", "a", "--dry-run"}))
+	assert.Equal(t, run{options: runOptions{OutputDir: "out", Tags: []string{"a"}}, dryRun: true}, got)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"run", "--tag", "b"}))
+	assert.Equal(t, run{options: runOptions{OutputDir: ".", Tags: []string{"b"}}}, got)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "third")
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(ctx, []string{"run"}))
+	assert.Equal(t, run{options: runOptions{OutputDir: "."}, ctx: "third"}, got)
+}
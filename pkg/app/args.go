@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// Arg describes a positional argument of a command.
+type Arg struct {
+	// Name is shown in the usage line, e.g. url for `<url>`.
+	Name string
+	// Usage is shown in the Arguments section of the help.
+	Usage string
+	// Optional args may be omitted, they must follow the required ones.
+	Optional bool
+	// Variadic accepts any number of values, only the last arg can be variadic.
+	Variadic bool
+	// Validate checks every value of the arg, optional.
+	Validate func(value string) error
+	// Complete completes the values of the arg, file names are completed when nil.
+	Complete cobra.CompletionFunc
+}
+
+// ArgsOptions are options bound to the positional args of their command.
+// SetArgs is called with the args before the options are completed and validated.
+type ArgsOptions interface {
+	SetArgs(args []string) error
+}
+
+// RunArgsFunc defines the startup callback function of a command receiving its
+// positional args.
+type RunArgsFunc func(ctx context.Context, option CliOptions, args []string) error
+
+type argsContextKey struct{}
+
+// WithCommandArgs declares the positional args of the command. The number of
+// args and every value are checked before the command is run, the args are
+// added to the usage line and to the help.
+func WithCommandArgs(args ...Arg) CommandOption {
+	return func(c *Command) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// WithCommandRunArgsFunc functional options pattern to set a RunCommandFunc
+// receiving the positional args.
+func WithCommandRunArgsFunc(run RunArgsFunc) CommandOption {
+	return func(c *Command) {
+		c.runFunc = run.withArgs()
+	}
+}
+
+// withArgs adapts a RunArgsFunc to a RunContextFunc reading the args from the context.
+func (run RunArgsFunc) withArgs() RunContextFunc {
+	return func(ctx context.Context, option CliOptions) error {
+		return run(ctx, option, ArgsFromContext(ctx))
+	}
+}
+
+// ArgsFromContext returns the positional args of the command being run, it is
+// set in the context passed to the middlewares and run funcs.
+func ArgsFromContext(ctx context.Context) []string {
+	args, _ := ctx.Value(argsContextKey{}).([]string)
+	return args
+}
+
+// runContext returns the context passed to the middlewares and the run func of cmd.
+func runContext(cmd *cobra.Command, args []string) context.Context {
+	ctx := context.WithValue(cmd.Context(), commandContextKey{}, cmd)
+	return context.WithValue(ctx, argsContextKey{}, args)
+}
+
+// bindArgs passes args to options implementing ArgsOptions.
+func bindArgs(options CliOptions, args []string) error {
+	if argsOptions, ok := options.(ArgsOptions); ok {
+		return argsOptions.SetArgs(args)
+	}
+	return nil
+}
+
+// argsUsage returns the args as shown in the usage line, e.g. `<url> [file.pdf...]`.
+func argsUsage(args []Arg) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, arg.usage())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (arg Arg) usage() string {
+	name := arg.Name
+	if arg.Variadic {
+		name += "..."
+	}
+	if arg.Optional {
+		return "[" + name + "]"
+	}
+	return "<" + name + ">"
+}
+
+// checkArgSpecs panics when the args of the command name are misdeclared: a
+// required arg follows an optional one or a variadic arg is not the last one,
+// the values would be bound to the wrong args otherwise.
+func checkArgSpecs(name string, specs []Arg) {
+	for i, spec := range specs {
+		if spec.Variadic && i < len(specs)-1 {
+			panic(fmt.Sprintf("app: variadic arg %s of command %s must be the last one", spec.usage(), name))
+		}
+		if i > 0 && specs[i-1].Optional && !spec.Optional {
+			panic(fmt.Sprintf("app: required arg %s of command %s follows the optional arg %s", spec.usage(), name, specs[i-1].usage()))
+		}
+	}
+}
+
+// positionalArgs returns the cobra.PositionalArgs checking the number of args
+// and their values, specs must not be empty.
+func positionalArgs(specs []Arg) cobra.PositionalArgs {
+	minArgs, maxArgs := 0, len(specs)
+	for _, spec := range specs {
+		if !spec.Optional {
+			minArgs++
+		}
+		if spec.Variadic {
+			maxArgs = -1
+		}
+	}
+
+	return func(cmd *cobra.Command, args []string) error {
+		var err error
+		switch {
+		case len(args) < minArgs:
+			err = i18n.Errorf("app.args.too_few", cmd.CommandPath(), minArgs, argsUsage(specs), len(args))
+		case maxArgs >= 0 && len(args) > maxArgs:
+			err = i18n.Errorf("app.args.too_many", cmd.CommandPath(), maxArgs, argsUsage(specs), len(args))
+		}
+		for i := 0; err == nil && i < len(args); i++ {
+			spec := specs[min(i, len(specs)-1)]
+			if spec.Validate == nil {
+				continue
+			}
+			if verr := spec.Validate(args[i]); verr != nil {
+				err = i18n.Errorf("app.args.invalid", spec.usage(), args[i], verr)
+			}
+		}
+		if err != nil {
+			return NewExitError(ExitCodeInvalidOptions, i18n.FromContext(cmd.Context()).T("app.hint.help", cmd.CommandPath()), err)
+		}
+		return nil
+	}
+}
+
+// completeArgs completes the arg at the position of the word being completed,
+// specs must not be empty.
+func completeArgs(specs []Arg) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= len(specs) && !specs[len(specs)-1].Variadic {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		spec := specs[min(len(args), len(specs)-1)]
+		if spec.Complete == nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return spec.Complete(cmd, args, toComplete)
+	}
+}
+
+// printArguments prints the Arguments section of the help.
+func printArguments(w io.Writer, p *i18n.Printer, specs []Arg, cols int) {
+	if len(specs) == 0 {
+		return
+	}
+	rows := make([][2]string, 0, len(specs))
+	for _, spec := range specs {
+		rows = append(rows, [2]string{spec.usage(), p.T(spec.Usage)})
+	}
+	fmt.Fprintf(w, "\n%s\n", cli.Heading(p.T("app.help.arguments")))
+	cli.PrintDefinitions(w, rows, cols)
+}
@@ -0,0 +1,128 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type argsOptions struct {
+	Files []string
+}
+
+func (o *argsOptions) Flags() (fss cli.NamedFlagSets) { return fss }
+
+func (o *argsOptions) Validate() []error { return nil }
+
+func (o *argsOptions) SetArgs(args []string) error {
+	o.Files = args
+	return nil
+}
+
+func Test_CommandArgs(t *testing.T) {
+	pdf := func(value string) error {
+		if !strings.HasSuffix(value, ".pdf") {
+			return errors.New("not a pdf file")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		output string
+		files  []string
+	}{
+		{
+			name:  "required and variadic",
+			args:  []string{"convert", "out", "a.pdf", "b.pdf"},
+			files: []string{"out", "a.pdf", "b.pdf"},
+		},
+		{
+			name:   "missing required",
+			args:   []string{"convert"},
+			code:   app.ExitCodeInvalidOptions,
+			output: `"test convert" requires at least 2 argument(s) <dir> <file.pdf...>, got 0`,
+		},
+		{
+			name:   "invalid value",
+			args:   []string{"convert", "out", "a.txt"},
+			code:   app.ExitCodeInvalidOptions,
+			output: `invalid argument <file.pdf...> "a.txt": not a pdf file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []string
+			opts := &argsOptions{}
+			a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+				app.NewCommand("convert", "convert pdf files", app.WithCommandOptions(opts),
+					app.WithCommandArgs(
+						app.Arg{Name: "dir", Usage: "output dir"},
+						app.Arg{Name: "file.pdf", Usage: "files to convert", Variadic: true, Validate: pdf},
+					),
+					app.WithCommandRunArgsFunc(func(ctx context.Context, option app.CliOptions, args []string) error {
+						received = args
+						return nil
+					})),
+			))
+			var stderr bytes.Buffer
+			a.Command().SetErr(&stderr)
+
+			assert.Equal(t, tt.code, a.RunWithArgs(context.Background(), tt.args))
+			assert.Contains(t, stderr.String(), tt.output)
+			assert.Equal(t, tt.files, received)
+			assert.Equal(t, tt.files, opts.Files)
+		})
+	}
+}
+
+func Test_CommandArgsHelp(t *testing.T) {
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("download", "download videos", app.WithCommandArgs(app.Arg{Name: "url", Usage: "urls to download", Optional: true, Variadic: true}),
+			app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })),
+	))
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"download", "--help"}))
+	assert.Contains(t, stdout.String(), "Usage:\n  test download [url...] [flags]\n")
+	assert.Contains(t, stdout.String(), "Arguments:\n  [url...]   urls to download\n")
+}
+
+func Test_CommandArgsInvalidSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []app.Arg
+		want string
+	}{
+		{
+			name: "required after optional",
+			args: []app.Arg{{Name: "src", Optional: true}, {Name: "dst"}},
+			want: "app: required arg <dst> of command copy follows the optional arg [src]",
+		},
+		{
+			name: "variadic not last",
+			args: []app.Arg{{Name: "src", Variadic: true}, {Name: "dst"}},
+			want: "app: variadic arg <src...> of command copy must be the last one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.PanicsWithValue(t, tt.want, func() {
+				app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+					app.NewCommand("copy", "copy files", app.WithCommandArgs(tt.args...)),
+				))
+			})
+		})
+	}
+}
@@ -1,12 +1,9 @@
 package app
 
 import (
-	"fmt"
-	"github.com/fatih/color"
 	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/log"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	"os"
 	"runtime"
 	"strings"
 )
@@ -18,7 +15,23 @@ type Command struct {
 	desc     string
 	options  CliOptions
 	commands []*Command
-	runFunc  RunFunc
+	runFunc  RunContextFunc
+	// args: the positional args of the command.
+	args []Arg
+	// middlewares: wrap the run func of the command and of its subcommands.
+	middlewares []Middleware
+	// aliases: other names the command can be called with.
+	aliases []string
+	// long: description shown by help instead of desc.
+	long     string
+	examples []string
+	// deprecated: -not empty a warning with the message is printed when the command is used.
+	deprecated string
+	// hidden: -true the command is left out of help and list.
+	hidden       bool
+	experimental bool
+	// group: title the command is listed under in the help of its parent.
+	group string
 }
 
 // NewCommand creates a new sub command instance based on the given command name and other options.
@@ -40,6 +53,13 @@ type CommandOption func(*Command)
 
 // WithCommandRunFunc functional options pattern to set RunCommandFunc
 func WithCommandRunFunc(run RunFunc) CommandOption {
+	return func(c *Command) {
+		c.runFunc = run.withContext()
+	}
+}
+
+// WithCommandRunContextFunc functional options pattern to set a context aware RunCommandFunc
+func WithCommandRunContextFunc(run RunContextFunc) CommandOption {
 	return func(c *Command) {
 		c.runFunc = run
 	}
@@ -51,6 +71,71 @@ func WithCommandOptions(opt CliOptions) CommandOption {
 	}
 }
 
+// WithSubCommands adds nested subcommands to the command, e.g. `pdf2docx gui`.
+func WithSubCommands(cmds ...*Command) CommandOption {
+	return func(c *Command) {
+		c.commands = append(c.commands, cmds...)
+	}
+}
+
+// WithAliases sets other names the command can be called with, e.g. `dl` for `download`.
+func WithAliases(aliases ...string) CommandOption {
+	return func(c *Command) {
+		c.aliases = append(c.aliases, aliases...)
+	}
+}
+
+// WithLong sets the long description shown by the help of the command.
+func WithLong(long string) CommandOption {
+	return func(c *Command) {
+		c.long = long
+	}
+}
+
+// WithExamples adds usage examples shown by the help of the command.
+func WithExamples(examples ...string) CommandOption {
+	return func(c *Command) {
+		c.examples = append(c.examples, examples...)
+	}
+}
+
+// WithDeprecated marks the command as deprecated, msg is printed as a warning
+// when the command is used, e.g. "use convert instead".
+func WithDeprecated(msg string) CommandOption {
+	return func(c *Command) {
+		c.deprecated = msg
+	}
+}
+
+// WithHidden hides the command from help and list, it can still be run.
+func WithHidden() CommandOption {
+	return func(c *Command) {
+		c.hidden = true
+	}
+}
+
+// WithExperimental marks the command as experimental.
+func WithExperimental() CommandOption {
+	return func(c *Command) {
+		c.experimental = true
+	}
+}
+
+// WithGroup lists the command below title in the help of its parent.
+func WithGroup(title string) CommandOption {
+	return func(c *Command) {
+		c.group = title
+	}
+}
+
+// name returns the name of the command, the first word of its usage.
+func (c *Command) name() string {
+	if fields := strings.Fields(c.usage); len(fields) > 0 {
+		return fields[0]
+	}
+	return c.usage
+}
+
 // FormatBaseName is formatted as an executable file name under different
 // operating systems according to the given name.
 func FormatBaseName(basename string) string {
@@ -63,52 +148,95 @@ func FormatBaseName(basename string) string {
 	return basename
 }
 
-// conversion customize Command  to cobra.Command
-func (c *Command) cobraCommand() *cobra.Command {
+// conversion customize Command  to cobra.Command, a is the app the command
+// belongs to, parent are the flag sections and middlewares the middlewares
+// inherited from the parent command.
+func (c *Command) cobraCommand(a *App, parent cli.NamedFlagSets, middlewares []Middleware) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   c.usage,
-		Short: c.desc,
+		Use:     c.usage,
+		Aliases: c.aliases,
+		Hidden:  c.hidden,
+		GroupID: c.group,
+	}
+	a.setText(&cmd.Short, c.desc)
+	a.setText(&cmd.Long, c.long)
+	if len(c.args) > 0 {
+		checkArgSpecs(c.name(), c.args)
+		if len(strings.Fields(c.usage)) == 1 {
+			cmd.Use = c.usage + " " + argsUsage(c.args)
+		}
+		cmd.Args = positionalArgs(c.args)
+		cmd.ValidArgsFunction = completeArgs(c.args)
+	}
+	if len(c.examples) > 0 {
+		cmd.Example = "  " + strings.ReplaceAll(strings.Join(c.examples, "\n"), "\n", "\n  ")
+	}
+	cmd.Annotations = map[string]string{annotationOptions: "true"}
+	if c.deprecated != "" {
+		cmd.Annotations[annotationDeprecated] = c.deprecated
+	}
+	if c.experimental {
+		cmd.Annotations[annotationExperimental] = "true"
 	}
-	cmd.SetOut(os.Stdout)
-	cmd.SetErr(os.Stderr)
+	// output goes to the writers of the root command.
 	cmd.Flags().SortFlags = false
+
+	namedFlagSets := cli.NamedFlagSets{}
+	if c.options != nil {
+		namedFlagSets = optionFlags(c.options, c.name())
+	}
+	inherited := addFlagSections(cmd, namedFlagSets, parent)
+	middlewares = append(append([]Middleware{}, middlewares...), c.middlewares...)
+	if err := registerCompletions(cmd, namedFlagSets, c.options); err != nil {
+		log.Warnf("failed to register completions of %s: %v", c.name(), err)
+	}
+
 	if len(c.commands) > 0 {
 		for _, command := range c.commands {
-			cmd.AddCommand(command.cobraCommand())
+			cmd.AddCommand(command.cobraCommand(a, inherited, middlewares))
 		}
+		a.addCommandGroups(cmd)
 	}
 	if c.runFunc != nil {
-		cmd.RunE = c.runCommand
-	}
-	namedFlagSets := cli.NamedFlagSets{}
-	if c.options != nil {
-		namedFlagSets = c.options.Flags()
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return c.runCommand(a, cmd, args, namedFlagSets, middlewares)
+		}
 	}
 
+	// help shows the own sections first, then the inherited ones.
+	helpFlagSets := cli.NamedFlagSets{}
+	helpFlagSets.Merge(namedFlagSets)
+	helpFlagSets.Merge(parent)
+
 	// to add --help flag to command
-	addCmdTemplate(cmd, namedFlagSets)
+	a.addHelpCommandFlag(c.usage, cmd.Flags())
+	a.addCmdTemplate(cmd, helpFlagSets, c.args...)
 
 	return cmd
 }
 
-func (c *Command) runCommand(cmd *cobra.Command, args []string) error {
+func (c *Command) runCommand(a *App, cmd *cobra.Command, args []string, fss cli.NamedFlagSets, middlewares []Middleware) error {
+	a.warnDeprecated(cmd)
 	cli.InitFlags(cmd.Flags())
-	if c.options != nil {
-		err := viper.BindPFlags(cmd.Flags())
-		if err != nil {
-			return err
-		}
-		err = viper.Unmarshal(c.options)
-		if err != nil {
-			return err
-		}
+	v, err := a.resolveOptions(cmd, commandPath(cmd), c.options)
+	if err != nil {
+		return err
+	}
+	if a.printConfig != "" {
+		return a.printOptionSources(cmd, commandPath(cmd), v, c.options)
+	}
+	if err := a.checkFlagGroups(cmd, commandPath(cmd), fss, c.args, args); err != nil {
+		return err
+	}
+	if err := bindArgs(c.options, args); err != nil {
+		return err
+	}
+	if err := a.applyOptionRules(c.options); err != nil {
+		return err
 	}
 
 	if c.runFunc != nil {
-		if err := c.runFunc(c.options); err != nil {
-			fmt.Printf("%v %v\n", color.RedString("Error:"), err)
-			os.Exit(1)
-		}
+		return a.runWithPlan(cmd, args, c.runFunc, c.options, middlewares)
 	}
 	return nil
 }
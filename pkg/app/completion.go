@@ -0,0 +1,68 @@
+package app
+
+import (
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// registerCompletions registers the completion functions of options and of the
+// flags of fss marked by cli.MarkValuesCompletion on cmd. The functions of
+// options replace the completion hints of the flags, e.g. of a cli.File.
+func registerCompletions(cmd *cobra.Command, fss cli.NamedFlagSets, options CliOptions) error {
+	var errs []error
+	completions := map[string]cobra.CompletionFunc{}
+	if completionOptions, ok := options.(CompletionOptions); ok {
+		completions = completionOptions.FlagCompletions()
+	}
+	for _, name := range fss.Order {
+		fss.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+			if _, ok := completions[f.Name]; ok {
+				// cobra completes the files and the dirs of the annotations before calling fn.
+				delete(f.Annotations, cobra.BashCompFilenameExt)
+				delete(f.Annotations, cobra.BashCompSubdirsInDir)
+				return
+			}
+			if values, ok := cli.CompletionValues(f); ok {
+				errs = append(errs, cmd.RegisterFlagCompletionFunc(f.Name,
+					cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)))
+			}
+		})
+	}
+	for name, fn := range completions {
+		errs = append(errs, cmd.RegisterFlagCompletionFunc(name, fn))
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *App) addCompletionCmd(name string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "completion [bash|zsh|fish|powershell]",
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, out := cmd.Root(), cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			}
+			return i18n.Errorf("app.completion.unsupported", args[0])
+		},
+	}
+	a.setText(&cmd.Short, "app.completion.short")
+	a.setText(&cmd.Long, "app.completion.long", name)
+	return cmd
+}
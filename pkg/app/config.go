@@ -1,53 +1,239 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 )
 
 const configFlagName = "config"
 
-// a parameter reception config flag parse.
-var cfgFile string
+// globalConfigSection is the configuration file section shared by all commands.
+const globalConfigSection = "global"
 
-func init() {
-	pflag.StringVarP(&cfgFile, "config", "c", cfgFile, "Read configuration from specified `FILE`, "+
-		"support JSON, TOML, YAML, HCL, or Java properties formats.")
+// config is the configuration state owned by an App, it is shared with the
+// subcommands of the App but never with other Apps.
+type config struct {
+	commandName string
+	// file: a parameter reception config flag parse.
+	file string
+	// viper: configuration read from the file and the environment.
+	viper  *viper.Viper
+	loaded bool
+}
+
+func newConfig(commandName string) *config {
+	return &config{
+		commandName: commandName,
+		viper:       newViper(commandName),
+	}
+}
+
+// newViper creates a viper instance reading the environment variables prefixed
+// with the command names, e.g. TOOL_DOWNLOAD_OUTPUT_DIR.
+func newViper(commandNames ...string) *viper.Viper {
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetEnvPrefix(envPrefix(commandNames))
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	return v
+}
+
+// envKeyReplacer maps option keys to environment variable names.
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+func envPrefix(commandNames []string) string {
+	return strings.Replace(strings.ToUpper(strings.Join(commandNames, "_")), "-", "_", -1)
+}
+
+// envName returns the environment variable read for key by the viper created
+// with newViper(commandNames...).
+func envName(commandNames []string, key string) string {
+	return envPrefix(commandNames) + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// decodeHook decodes the resolved options, it extends the default hooks of
+// viper to set the fields implementing pflag.Value, e.g. cli.File, with Set.
+// mapstructure prints the errors of the hooks as soon as they are returned, so
+// they are printed in the language of p.
+func decodeHook(p *i18n.Printer) viper.DecoderConfigOption {
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		func(from, to reflect.Value) (interface{}, error) {
+			value, err := cli.DecodeValueHook(from, to)
+			return value, p.Localize(err)
+		},
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+}
+
+// decodeErrors splits the error returned by viper.Unmarshal into the errors of
+// the options.
+func decodeErrors(err error) []error {
+	if joined, ok := errors.Unwrap(err).(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// bindFlagEnvs binds the flags of fs to the environment variables set by their
+// env tag, they are read after the ones derived from the command names.
+func bindFlagEnvs(v *viper.Viper, fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if env, ok := cli.FlagEnv(f); ok {
+			_ = v.BindEnv(f.Name, env)
+		}
+	})
 }
 
 // addConfigFlag adds flags for a specific server to the specified FlagSet object.
-func addConfigFlag(commandName string, fs *pflag.FlagSet) {
-	fs.AddFlag(pflag.Lookup(configFlagName))
-
-	// env
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix(strings.Replace(strings.ToUpper(commandName), "-", "_", -1))
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
-
-	cobra.OnInitialize(func() {
-		if cfgFile != "" {
-			viper.SetConfigFile(cfgFile)
-		} else { // if no set --config flag
-			// search for now dir
-			viper.AddConfigPath(".")
-
-			if names := strings.Split(commandName, "-"); len(names) > 1 {
-				// TODO add search path homedir
-				// viper.AddConfigPath(filepath.Join(homedir.HomeDir(), "."+names[0]))
-				viper.AddConfigPath(filepath.Join("/etc", names[0]))
-			}
+func (c *config) addConfigFlag(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.file, configFlagName, "c", c.file, "")
+}
+
+// reset forgets the configuration read by a previous run, every run reads the
+// file given by its own --config flag.
+func (c *config) reset() {
+	c.viper = newViper(c.commandName)
+	c.loaded = false
+}
 
-			viper.SetConfigName(commandName)
+// load reads the configuration file once per run. A missing file is only an
+// error when it was given by the --config flag.
+func (c *config) load() error {
+	if c.loaded {
+		return nil
+	}
+	c.loaded = true
+
+	if c.file != "" {
+		c.viper.SetConfigFile(c.file)
+	} else { // if no set --config flag
+		for _, path := range c.searchPaths() {
+			c.viper.AddConfigPath(path)
 		}
 
-		if err := viper.ReadInConfig(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to read configuration file(%s): %v\n", cfgFile, err)
-			os.Exit(1)
+		c.viper.SetConfigName(c.commandName)
+	}
+
+	if err := c.viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if c.file == "" && errors.As(err, &notFound) {
+			return nil
 		}
-	})
+		return i18n.Errorf("app.config.read", c.file, err)
+	}
+
+	return nil
+}
+
+// searchPaths returns the directories searched for the configuration file when
+// the --config flag is not set, in order: the working dir, the user config dir
+// ($XDG_CONFIG_HOME or ~/.config on linux), the home dir and /etc.
+func (c *config) searchPaths() []string {
+	base := strings.Split(c.commandName, "-")[0]
+	paths := []string{"."}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, base))
+	}
+	if dir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "."+base))
+	}
+	return append(paths, filepath.Join("/etc", base))
+}
+
+// defaultFile returns the file written by `config init` when the --config flag
+// is not set.
+func (c *config) defaultFile() (string, error) {
+	if c.file != "" {
+		return c.file, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, strings.Split(c.commandName, "-")[0], c.commandName+".yaml"), nil
+}
+
+// fileUsed returns the path of the configuration file in use.
+func (c *config) fileUsed() string {
+	return c.viper.ConfigFileUsed()
+}
+
+// commandViper creates the viper resolving the options of the command at path,
+// e.g. ["pdf2docx", "convert"]. The options are resolved in the order
+// flag > env > command section > global section > defaults, where the command
+// section is the path joined by dots in the configuration file and the top
+// level of the file for the root command.
+func (c *config) commandViper(path []string) (*viper.Viper, error) {
+	v := newViper(append([]string{c.commandName}, path...)...)
+
+	sections := []*viper.Viper{c.viper.Sub(globalConfigSection), c.viper}
+	if len(path) > 0 {
+		sections[1] = c.viper.Sub(strings.Join(path, "."))
+	}
+	for _, section := range sections {
+		if section == nil {
+			continue
+		}
+		if err := v.MergeConfigMap(section.AllSettings()); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// commandPath returns the names of cmd and its parents without the root command.
+func commandPath(cmd *cobra.Command) []string {
+	return strings.Fields(cmd.CommandPath())[1:]
+}
+
+// location returns the position of the dotted key path in the configuration
+// file as file:line, the line is omitted for formats other than YAML and JSON.
+func (c *config) location(path []string) string {
+	file := c.fileUsed()
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return file
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return file
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return file
+	}
+
+	node := doc.Content[0]
+	line := 0
+	for _, key := range path {
+		var next *yaml.Node
+		for i := 0; node.Kind == yaml.MappingNode && i+1 < len(node.Content); i += 2 {
+			// viper keys are case-insensitive.
+			if strings.EqualFold(node.Content[i].Value, key) {
+				line, next = node.Content[i].Line, node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return file
+		}
+		node = next
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
 }
@@ -0,0 +1,465 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configEntry is an options struct registered on the app with the path of the
+// command owning it, the root command has an empty path.
+type configEntry struct {
+	path    []string
+	options CliOptions
+}
+
+// configEntries returns the options of the app and of all its subcommands.
+func (a *App) configEntries() []configEntry {
+	var entries []configEntry
+	if a.options != nil {
+		entries = append(entries, configEntry{options: a.options})
+	}
+
+	var walk func(parent []string, commands []*Command)
+	walk = func(parent []string, commands []*Command) {
+		for _, c := range commands {
+			path := append(append([]string{}, parent...), c.name())
+			if c.options != nil {
+				entries = append(entries, configEntry{path: path, options: c.options})
+			}
+			walk(path, c.commands)
+		}
+	}
+	walk(nil, a.commands)
+
+	return entries
+}
+
+// configNode builds the YAML document of every registered option. The values
+// are resolved by the viper returned by resolve for the command path. When
+// template is set, the document starts with the global section, the usage of
+// the flags is added as comments and the deprecated options and the ones with
+// a zero value are left out.
+func (a *App) configNode(resolve func(path []string) (*viper.Viper, error), template bool) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	if template {
+		mappingNode(root, []string{globalConfigSection})
+	}
+	for _, entry := range a.configEntries() {
+		v, err := resolve(entry.path)
+		if err != nil {
+			return nil, err
+		}
+
+		fss := a.pathFlags(entry.path, entry.options)
+		for _, name := range fss.Order {
+			fs := fss.FlagSets[name]
+			if err := v.BindPFlags(fs); err != nil {
+				return nil, err
+			}
+			bindFlagEnvs(v, fs)
+
+			// the global section of the root options is shared by all commands.
+			section := entry.path
+			if len(entry.path) == 0 && name == globalFlagSetName {
+				section = []string{globalConfigSection}
+			}
+
+			var errs []error
+			fs.VisitAll(func(f *pflag.Flag) {
+				if template && (f.Deprecated != "" || isZeroValue(v.Get(f.Name))) {
+					return
+				}
+				value := &yaml.Node{}
+				if err := value.Encode(v.Get(f.Name)); err != nil {
+					errs = append(errs, err)
+					return
+				}
+				key := &yaml.Node{Kind: yaml.ScalarNode, Value: f.Name}
+				if template {
+					key.HeadComment = f.Usage
+				}
+				mapping := mappingNode(root, section)
+				mapping.Content = append(mapping.Content, key, value)
+			})
+			if len(errs) > 0 {
+				return nil, errs[0]
+			}
+		}
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+}
+
+// mappingNode returns the mapping at path below node, missing mappings are
+// created.
+func mappingNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.MappingNode {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, next)
+		}
+		node = next
+	}
+	return node
+}
+
+// encodeYAML encodes node with an indent of two spaces.
+func encodeYAML(node interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// effectiveConfig returns the merged configuration of every registered option.
+func (a *App) effectiveConfig() (map[string]interface{}, error) {
+	if err := a.config.load(); err != nil {
+		return nil, err
+	}
+	node, err := a.configNode(a.config.commandViper, false)
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]interface{}{}
+	if err := node.Decode(&settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// addConfigCmd creates the config command group.
+func (a *App) addConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "config",
+	}
+	a.setText(&cmd.Short, "app.config.short")
+	cmd.AddCommand(a.configViewCmd(), a.configGetCmd(), a.configSetCmd(), a.configInitCmd(), a.configPathCmd())
+	return cmd
+}
+
+func (a *App) configViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "view",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, err := a.effectiveConfig()
+			if err != nil {
+				return err
+			}
+			data, err := encodeYAML(settings)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+	a.setText(&cmd.Short, "app.config.view.short")
+	return cmd
+}
+
+func (a *App) configGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "get KEY",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, err := a.effectiveConfig()
+			if err != nil {
+				return err
+			}
+
+			value, ok := lookupKey(settings, args[0])
+			if !ok && a.config.viper.IsSet(args[0]) {
+				value, ok = a.config.viper.Get(args[0]), true
+			}
+			if !ok {
+				return i18n.Errorf("app.config.get.not_set", args[0])
+			}
+
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				data, err := encodeYAML(value)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			default:
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), value)
+				return err
+			}
+		},
+	}
+	a.setText(&cmd.Short, "app.config.get.short")
+	return cmd
+}
+
+// lookupKey returns the value of the dotted key in settings.
+func lookupKey(settings map[string]interface{}, key string) (interface{}, bool) {
+	var value interface{} = settings
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func (a *App) configSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "set KEY VALUE",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.checkConfigKey(cmd, args[0], args[1]); err != nil {
+				return err
+			}
+			if err := a.config.load(); err != nil {
+				return err
+			}
+			file := a.config.fileUsed()
+			if file == "" {
+				var err error
+				if file, err = a.config.defaultFile(); err != nil {
+					return err
+				}
+			}
+
+			return a.runWithPlan(cmd, args, func(ctx context.Context, _ CliOptions) error {
+				if IsDryRun(ctx) {
+					RecordAction(ctx, Action{Creates: []string{file}})
+					return nil
+				}
+				if err := setConfigKey(file, args[0], args[1]); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), a.printer.T("app.config.set.done", args[0], file))
+				return err
+			}, nil, nil)
+		},
+	}
+	a.setText(&cmd.Short, "app.config.set.short")
+	return cmd
+}
+
+// checkConfigKey validates the value of the dotted key set by config set. The
+// key must be read by a registered option, either from the section of its
+// command or from the global section, or be the language of the messages.
+func (a *App) checkConfigKey(cmd *cobra.Command, key, value string) error {
+	if key == langFlagName || key == globalConfigSection+"."+langFlagName {
+		if _, ok := i18n.Match(value); !ok {
+			return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.hint.help", cmd.CommandPath()),
+				i18n.Errorf("i18n.unsupported", value, strings.Join(i18n.Languages(), ", ")))
+		}
+		return nil
+	}
+
+	known := false
+	for _, entry := range a.configEntries() {
+		fss := a.pathFlags(entry.path, entry.options)
+		for _, name := range fss.Order {
+			section := entry.path
+			if len(entry.path) == 0 && name == globalFlagSetName {
+				section = []string{globalConfigSection}
+			}
+			fss.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+				if key == strings.Join(append(append([]string{}, section...), f.Name), ".") ||
+					key == globalConfigSection+"."+f.Name {
+					known = true
+				}
+			})
+		}
+	}
+	if !known {
+		return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.config.set.unknown_hint", a.commandName),
+			i18n.Errorf("app.config.set.unknown", key))
+	}
+	return nil
+}
+
+// setConfigKey sets the dotted key to value in file, creating it if needed.
+// YAML files are edited in place to keep their comments, other formats are
+// rewritten by viper.
+func setConfigKey(file, key, value string) error {
+	// the value is typed as in a YAML file, e.g. true is a bool.
+	var typed interface{}
+	if err := yaml.Unmarshal([]byte(value), &typed); err != nil || typed == nil {
+		typed = value
+	}
+
+	ext := strings.ToLower(filepath.Ext(file))
+	if ext != ".yaml" && ext != ".yml" {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if _, err := os.Stat(file); err == nil {
+			if err := v.ReadInConfig(); err != nil {
+				return err
+			}
+		}
+		v.Set(key, typed)
+		return v.WriteConfigAs(file)
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	if data, err := os.ReadFile(file); err == nil {
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := yaml.Unmarshal(data, doc); err != nil {
+				return err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return i18n.Errorf("app.config.not_mapping", file)
+	}
+
+	path := strings.Split(key, ".")
+	mapping := mappingNode(doc.Content[0], path[:len(path)-1])
+	valueNode := &yaml.Node{}
+	if err := valueNode.Encode(typed); err != nil {
+		return err
+	}
+
+	name := path[len(path)-1]
+	found := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			// keep the comments attached to the old value.
+			valueNode.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = valueNode
+			found = true
+			break
+		}
+	}
+	if !found {
+		// an empty mapping, e.g. the global section of config init, is written
+		// in flow style.
+		mapping.Style = 0
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, valueNode)
+	}
+
+	data, err := encodeYAML(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+func (a *App) configInitCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:  "init",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := a.config.defaultFile()
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(file); err == nil && !force {
+				return NewExitError(ExitCodeError, a.printer.T("app.config.init.exists_hint"),
+					i18n.Errorf("app.config.init.exists", file))
+			}
+
+			data, err := a.defaultConfig()
+			if err != nil {
+				return err
+			}
+			return a.runWithPlan(cmd, args, func(ctx context.Context, _ CliOptions) error {
+				if IsDryRun(ctx) {
+					RecordAction(ctx, Action{Creates: []string{file}})
+					return nil
+				}
+				if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+					return err
+				}
+				if err := os.WriteFile(file, data, 0o644); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), a.printer.T("app.config.init.done", file))
+				return err
+			}, nil, nil)
+		},
+	}
+	a.setText(&cmd.Short, "app.config.init.short")
+	cmd.Flags().BoolVar(&force, "force", false, "")
+	a.setFlagUsage(cmd.Flags(), "force", "app.config.init.flag.force")
+	return cmd
+}
+
+// defaultConfig returns the commented configuration file holding the default
+// value of the registered options which are not zero.
+func (a *App) defaultConfig() ([]byte, error) {
+	node, err := a.configNode(func([]string) (*viper.Viper, error) {
+		return viper.New(), nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	node.HeadComment = a.printer.T("app.config.init.comment", a.commandName, a.commandName)
+	// the global section is the first one, see configNode.
+	node.Content[0].Content[0].HeadComment = a.printer.T("app.config.init.global",
+		langFlagName, strings.Join(i18n.Languages(), ", "))
+
+	return encodeYAML(node)
+}
+
+func (a *App) configPathCmd() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:  "path",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all {
+				for _, path := range a.config.searchPaths() {
+					fmt.Fprintln(cmd.OutOrStdout(), path)
+				}
+				return nil
+			}
+
+			if err := a.config.load(); err != nil {
+				return err
+			}
+			file := a.config.fileUsed()
+			if file == "" {
+				return NewExitError(ExitCodeError, a.printer.T("app.config.path.none_hint", a.commandName),
+					i18n.Errorf("app.config.path.none"))
+			}
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), file)
+			return err
+		},
+	}
+	a.setText(&cmd.Short, "app.config.path.short")
+	cmd.Flags().BoolVar(&all, "all", false, "")
+	a.setFlagUsage(cmd.Flags(), "all", "app.config.path.flag.all")
+	return cmd
+}
@@ -0,0 +1,408 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type configOptions struct {
+	OutputDir string `mapstructure:"output-dir"`
+}
+
+func (o *configOptions) Flags() (fss cli.NamedFlagSets) {
+	fss.FlagSet("config").StringVar(&o.OutputDir, "output-dir", o.OutputDir, "Output directory.")
+	return fss
+}
+
+func (o *configOptions) Validate() []error { return nil }
+
+func writeConfig(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte(content), 0o644))
+	return file
+}
+
+func Test_ConfigIsolation(t *testing.T) {
+	for _, dir := range []string{"first", "second", "third"} {
+		dir := dir
+		t.Run(dir, func(t *testing.T) {
+			t.Parallel()
+
+			file := writeConfig(t, "global:\n  output-dir: "+dir+"\n")
+			rootOpts, subOpts := &configOptions{}, &configOptions{}
+			var root, sub string
+			a := app.NewApp("test", "test",
+				app.WithSilence(),
+				app.WithOptions(rootOpts),
+				app.WithRunFunc(func(option app.CliOptions) error {
+					root = option.(*configOptions).OutputDir
+					return nil
+				}),
+				app.WithCommands(app.NewCommand("sub", "sub command",
+					app.WithCommandOptions(subOpts),
+					app.WithCommandRunFunc(func(option app.CliOptions) error {
+						sub = option.(*configOptions).OutputDir
+						return nil
+					}),
+				)),
+			)
+
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"--config", file}))
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"sub", "--config", file}))
+			assert.Equal(t, dir, root)
+			assert.Equal(t, dir, sub)
+
+			// a later run reads the file given by its own --config flag.
+			other := writeConfig(t, "global:\n  output-dir: "+dir+"-other\n")
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"sub", "--config", other}))
+			assert.Equal(t, dir+"-other", sub)
+		})
+	}
+}
+
+func Test_ConfigSections(t *testing.T) {
+	file := writeConfig(t, `
+global:
+  output-dir: global
+download:
+  output-dir: download
+pdf2docx:
+  convert:
+    output-dir: convert
+`)
+
+	var got string
+	capture := app.WithCommandRunFunc(func(option app.CliOptions) error {
+		got = option.(*configOptions).OutputDir
+		return nil
+	})
+	newApp := func() *app.App {
+		return app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+			app.NewCommand("download", "download", app.WithCommandOptions(&configOptions{OutputDir: "default"}), capture),
+			app.NewCommand("init", "init", app.WithCommandOptions(&configOptions{OutputDir: "default"}), capture),
+			app.NewCommand("pdf2docx", "pdf2docx", app.WithSubCommands(
+				app.NewCommand("convert", "convert", app.WithCommandOptions(&configOptions{OutputDir: "default"}), capture),
+			)),
+		))
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{name: "command section", args: []string{"download"}, want: "download"},
+		{name: "global section", args: []string{"init"}, want: "global"},
+		{name: "nested section", args: []string{"pdf2docx", "convert"}, want: "convert"},
+		{name: "nested env", env: "TOOL_PDF2DOCX_CONVERT_OUTPUT_DIR", args: []string{"pdf2docx", "convert"}, want: "env"},
+		{name: "env", env: "TOOL_DOWNLOAD_OUTPUT_DIR", args: []string{"download"}, want: "env"},
+		{name: "flag", env: "TOOL_DOWNLOAD_OUTPUT_DIR", args: []string{"download", "--output-dir", "flag"}, want: "flag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(tt.env, "env")
+			}
+			args := append(tt.args, "--config", file)
+			assert.Equal(t, app.ExitCodeOK, newApp().RunWithArgs(context.Background(), args))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got = ""
+	assert.Equal(t, app.ExitCodeOK, newApp().RunWithArgs(context.Background(), []string{"download"}))
+	assert.Equal(t, "default", got)
+}
+
+func Test_ConfigMissingFile(t *testing.T) {
+	a := app.NewApp("test", "test",
+		app.WithSilence(),
+		app.WithRunFunc(func(option app.CliOptions) error { return nil }),
+	)
+	a.Command().SetErr(&discard{})
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	assert.Equal(t, app.ExitCodeError, a.RunWithArgs(context.Background(), []string{"--config", missing}))
+	assert.Equal(t, app.ExitCodeOK, app.NewApp("test", "test",
+		app.WithSilence(),
+		app.WithRunFunc(func(option app.CliOptions) error { return nil }),
+	).RunWithArgs(context.Background(), []string{}))
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func Test_ConfigCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tool.yaml")
+	run := func(args ...string) (int, string) {
+		a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+			app.NewCommand("download", "download", app.WithCommandOptions(&configOptions{OutputDir: "."})),
+			app.NewCommand("upload", "upload", app.WithCommandOptions(&configOptions{})),
+		))
+		var stdout bytes.Buffer
+		a.Command().SetOut(&stdout)
+		a.Command().SetErr(&discard{})
+		code := a.RunWithArgs(context.Background(), append(args, "--config", file))
+		return code, stdout.String()
+	}
+
+	// a dry run prints the file it would write without writing it.
+	code, out := run("config", "init", "--dry-run")
+	assert.Equal(t, app.ExitCodeDryRunPending, code)
+	assert.Contains(t, out, file)
+	assert.NoFileExists(t, file)
+
+	code, _ = run("config", "init")
+	assert.Equal(t, app.ExitCodeOK, code)
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "download:\n  # Output directory.\n  output-dir: .\n")
+	// the global section is always written, the zero values are left out.
+	assert.Contains(t, string(data), "\nglobal: {}\n")
+	assert.NotContains(t, string(data), "upload")
+
+	code, _ = run("config", "init")
+	assert.Equal(t, app.ExitCodeError, code)
+
+	code, _ = run("config", "set", "download.nope", "1")
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+	code, _ = run("config", "set", "lang", "xx")
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+
+	code, _ = run("config", "set", "global.lang", "zh-CN")
+	assert.Equal(t, app.ExitCodeOK, code)
+	data, err = os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "\nglobal:\n  lang: zh-CN\n")
+	code, _ = run("config", "set", "global.lang", "en-US")
+	assert.Equal(t, app.ExitCodeOK, code)
+
+	code, _ = run("config", "set", "download.output-dir", "videos", "--dry-run")
+	assert.Equal(t, app.ExitCodeDryRunPending, code)
+	data, err = os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "  output-dir: .\n")
+
+	code, _ = run("config", "set", "download.output-dir", "videos")
+	assert.Equal(t, app.ExitCodeOK, code)
+	data, err = os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "  # Output directory.\n  output-dir: videos\n")
+
+	code, out = run("config", "get", "download.output-dir")
+	assert.Equal(t, app.ExitCodeOK, code)
+	assert.Equal(t, "videos\n", out)
+
+	t.Setenv("TOOL_DOWNLOAD_OUTPUT_DIR", "env")
+	code, out = run("config", "view")
+	assert.Equal(t, app.ExitCodeOK, code)
+	assert.Equal(t, "download:\n  output-dir: env\nupload:\n  output-dir: \"\"\n", out)
+
+	code, out = run("config", "path")
+	assert.Equal(t, app.ExitCodeOK, code)
+	assert.Equal(t, file+"\n", out)
+
+	code, _ = run("config", "get", "download.missing")
+	assert.Equal(t, app.ExitCodeError, code)
+}
+
+func Test_PrintConfig(t *testing.T) {
+	file := writeConfig(t, "global:\n  output-dir: global\n")
+	called := false
+	a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+		app.NewCommand("download", "download",
+			app.WithCommandOptions(&configOptions{OutputDir: "."}),
+			app.WithCommandRunFunc(func(option app.CliOptions) error {
+				called = true
+				return nil
+			}),
+		),
+		app.NewCommand("init", "init", app.WithCommandRunFunc(func(option app.CliOptions) error {
+			called = true
+			return nil
+		})),
+	))
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+	a.Command().SetErr(&discard{})
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(),
+		[]string{"download", "--config", file, "--print-config=json"}))
+	assert.JSONEq(t, `[{"name": "output-dir", "value": "global", "source": "`+file+`:2"}]`, stdout.String())
+	assert.False(t, called)
+
+	t.Setenv("TOOL_DOWNLOAD_OUTPUT_DIR", "env")
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(),
+		[]string{"download", "--config", file, "--print-config"}))
+	assert.Equal(t, "NAME        VALUE  SOURCE\noutput-dir  env    env:TOOL_DOWNLOAD_OUTPUT_DIR\n", stdout.String())
+
+	// a command without options prints an empty list instead of running.
+	stdout.Reset()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"init", "--print-config=json"}))
+	assert.Equal(t, "[]\n", stdout.String())
+	assert.False(t, called)
+
+	assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(context.Background(), []string{"config", "view", "--print-config"}))
+}
+
+type taggedOptions struct {
+	Token     string `mapstructure:"token" usage:"API token." env:"TEST_API_TOKEN" required:"true"`
+	OutputDir string `mapstructure:"output-dir" usage:"Output directory." default:"."`
+	Format    string `mapstructure:"format" usage:"Output format." enum:"mp4,flv"`
+}
+
+// Validate adds a check to the ones of the tags.
+func (o *taggedOptions) Validate() []error {
+	if o.OutputDir == "" {
+		return []error{errors.New("output directory is empty")}
+	}
+	return nil
+}
+
+func Test_TaggedOptions(t *testing.T) {
+	var got *taggedOptions
+	a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+		app.NewCommand("download", "download",
+			app.WithCommandOptions(&taggedOptions{}),
+			app.WithCommandRunFunc(func(option app.CliOptions) error {
+				got = option.(*taggedOptions)
+				return nil
+			}),
+		),
+	))
+	var stdout, stderr bytes.Buffer
+	a.Command().SetOut(&stdout)
+	a.Command().SetErr(&stderr)
+
+	assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(context.Background(), []string{"download", "--format", "avi"}))
+	assert.Contains(t, stderr.String(), "token is required")
+	assert.Contains(t, stderr.String(), `invalid format "avi", must be one of mp4, flv`)
+	assert.Nil(t, got)
+
+	stderr.Reset()
+	assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(context.Background(), []string{"download", "--output-dir", ""}))
+	assert.Contains(t, stderr.String(), "token is required")
+	assert.Contains(t, stderr.String(), "output directory is empty")
+	assert.Nil(t, got)
+
+	t.Setenv("TEST_API_TOKEN", "secret")
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"download", "--format", "mp4"}))
+	if assert.NotNil(t, got) {
+		assert.Equal(t, taggedOptions{Token: "secret", OutputDir: ".", Format: "mp4"}, *got)
+	}
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"download", "--print-config=json"}))
+	assert.Contains(t, stdout.String(), `"source": "env:TEST_API_TOKEN"`)
+}
+
+type valueOptions struct {
+	OutputDir cli.Dir `mapstructure:"output-dir" usage:"Output directory."`
+}
+
+func (o *valueOptions) Validate() []error { return nil }
+
+func Test_ConfigValues(t *testing.T) {
+	dir := t.TempDir()
+	var got cli.Dir
+	var stderr bytes.Buffer
+	run := func(file string) int {
+		a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+			app.NewCommand("download", "download",
+				app.WithCommandOptions(&valueOptions{OutputDir: cli.Dir{Create: true}}),
+				app.WithCommandRunFunc(func(option app.CliOptions) error {
+					got = option.(*valueOptions).OutputDir
+					return nil
+				}),
+			),
+		))
+		a.Command().SetErr(&stderr)
+		return a.RunWithArgs(context.Background(), []string{"download", "--config", file})
+	}
+
+	file := writeConfig(t, "download:\n  output-dir: "+filepath.Join(dir, "videos")+"\n")
+	assert.Equal(t, app.ExitCodeOK, run(file))
+	assert.Equal(t, cli.Dir{Path: filepath.Join(dir, "videos"), Create: true}, got)
+
+	file = writeConfig(t, "download:\n  output-dir: "+filepath.Join(file, "videos")+"\n")
+	assert.Equal(t, app.ExitCodeInvalidOptions, run(file))
+	assert.Contains(t, stderr.String(), "is not a directory")
+}
+
+type sourceOptions struct {
+	URL  string `mapstructure:"url" usage:"Address to download."`
+	File string `mapstructure:"file" usage:"File listing the addresses."`
+}
+
+func (o *sourceOptions) Validate() []error { return nil }
+
+func (o *sourceOptions) Flags() (fss cli.NamedFlagSets) {
+	fss = cli.StructFlags(o, "download")
+	fss.MarkOneRequired("url", "file")
+	fss.MarkMutuallyExclusive("url", "file")
+	return fss
+}
+
+func Test_FlagGroupsEnv(t *testing.T) {
+	run := func() (int, string) {
+		a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+			app.NewCommand("download", "download", app.WithCommandOptions(&sourceOptions{}),
+				app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })),
+		))
+		var stderr bytes.Buffer
+		a.Command().SetErr(&stderr)
+		return a.RunWithArgs(context.Background(), []string{"download"}), stderr.String()
+	}
+
+	// an empty variable is ignored by viper, it does not set the flag.
+	t.Setenv("TOOL_DOWNLOAD_URL", "")
+	code, stderr := run()
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+	assert.Contains(t, stderr, "one of --url or --file is required")
+
+	t.Setenv("TOOL_DOWNLOAD_URL", "https://www.bilibili.com/video/BV1")
+	code, _ = run()
+	assert.Equal(t, app.ExitCodeOK, code)
+}
+
+func Test_FlagGroupsConfig(t *testing.T) {
+	// the keys of the file holding an empty value do not set their option.
+	file := writeConfig(t, "download:\n  url: \"\"\n  file: \"\"\n")
+	run := func(args ...string) (int, string) {
+		a := app.NewApp("tool", "tool", app.WithSilence(), app.WithCommands(
+			app.NewCommand("download", "download", app.WithCommandOptions(&sourceOptions{}),
+				app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })),
+		))
+		var stderr bytes.Buffer
+		a.Command().SetErr(&stderr)
+		return a.RunWithArgs(context.Background(), append([]string{"download", "--config", file}, args...)), stderr.String()
+	}
+
+	code, stderr := run("--url", "https://a.b/c")
+	assert.Equal(t, app.ExitCodeOK, code, stderr)
+
+	code, stderr = run()
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+	assert.Contains(t, stderr, "one of --url or --file is required")
+	assert.NotContains(t, stderr, "can not be used together")
+
+	code, stderr = run("--url", "https://a.b/c", "--file", "urls.txt")
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+	assert.Contains(t, stderr, "can not be used together")
+
+	file = writeConfig(t, "download:\n  url: https://a.b/c\n")
+	code, stderr = run("--file", "urls.txt")
+	assert.Equal(t, app.ExitCodeInvalidOptions, code)
+	assert.Contains(t, stderr, "can not be used together")
+}
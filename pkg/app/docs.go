@@ -0,0 +1,567 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// formats of the reference docs written by the docs command.
+const (
+	docsFormatMan      = "man"
+	docsFormatMarkdown = "markdown"
+	docsFormatHTML     = "html"
+)
+
+var docsFormats = []string{docsFormatMan, docsFormatMarkdown, docsFormatHTML}
+
+// docPage is the reference of a command, it holds what its help shows.
+type docPage struct {
+	// Name is the command path joined by dashes, e.g. tool-download, it names
+	// the files and the anchors of the page.
+	Name     string
+	Path     string
+	Short    string
+	Long     string
+	Usage    string
+	Notices  []string
+	Aliases  string
+	Args     [][2]string
+	Examples string
+	Sections []docSection
+	Parent   *docLink
+	Commands []docLink
+}
+
+// docLink refers to the page of another command.
+type docLink struct {
+	Name  string
+	Path  string
+	Short string
+}
+
+// docSection is a flag section of a page.
+type docSection struct {
+	Title  string
+	Flags  []docFlag
+	Groups []string
+}
+
+// docFlag is a flag with the environment variables and the configuration key
+// its option is read from, if any.
+type docFlag struct {
+	Term   string
+	Usage  string
+	Envs   []string
+	Config string
+}
+
+// docTitles are the titles of the parts of the pages in the language of a printer.
+type docTitles struct {
+	Usage, Arguments, Aliases, Examples, Commands, SeeAlso string
+	Argument, Flag, Description, Env, Config               string
+}
+
+func newDocTitles(p *i18n.Printer) docTitles {
+	return docTitles{
+		Usage:       docTitle(p, "app.help.usage"),
+		Arguments:   docTitle(p, "app.help.arguments"),
+		Aliases:     docTitle(p, "app.help.aliases"),
+		Examples:    docTitle(p, "app.help.examples"),
+		Commands:    docTitle(p, "app.help.commands"),
+		SeeAlso:     p.T("app.docs.see_also"),
+		Argument:    p.T("app.docs.argument"),
+		Flag:        p.T("app.docs.flag"),
+		Description: p.T("app.docs.description"),
+		Env:         p.T("app.docs.env"),
+		Config:      p.T("app.docs.config_key"),
+	}
+}
+
+// docTitle returns the help heading key in the language of p without its colon.
+func docTitle(p *i18n.Printer, key string) string {
+	return strings.TrimSuffix(p.T(key), ":")
+}
+
+// addDocsCmd creates the hidden docs command writing the reference of every
+// command as man pages, Markdown and a single HTML page.
+func (a *App) addDocsCmd() *cobra.Command {
+	dir := "docs"
+	formats := append([]string{}, docsFormats...)
+	cmd := &cobra.Command{
+		Use:    "docs",
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, format := range formats {
+				if !slices.Contains(docsFormats, format) {
+					return NewExitError(ExitCodeInvalidOptions,
+						a.printer.T("app.output.hint", "format", strings.Join(docsFormats, ",")),
+						i18n.Errorf("app.flag.invalid_format", "format", format))
+				}
+			}
+			return a.runWithPlan(cmd, args, func(ctx context.Context, _ CliOptions) error {
+				files, err := a.writeDocs(ctx, dir, formats)
+				if IsDryRun(ctx) {
+					RecordAction(ctx, Action{Creates: files})
+					return err
+				}
+				for _, file := range files {
+					fmt.Fprintln(cmd.OutOrStdout(), file)
+				}
+				return err
+			}, nil, nil)
+		},
+	}
+	a.setText(&cmd.Short, "app.docs.short")
+	cmd.Flags().StringVar(&dir, "dir", dir, "")
+	a.setFlagUsage(cmd.Flags(), "dir", "app.docs.flag.dir")
+	cmd.Flags().StringSliceVar(&formats, "format", formats, "")
+	a.setFlagUsage(cmd.Flags(), "format", "app.docs.flag.format", strings.Join(docsFormats, ", "))
+	cli.MarkValuesCompletion(cmd.Flags(), "format", docsFormats...)
+	return cmd
+}
+
+// writeDocs writes the reference of the commands in formats to dir and returns
+// the files written. A dry run returns the files without writing them.
+func (a *App) writeDocs(ctx context.Context, dir string, formats []string) ([]string, error) {
+	if !IsDryRun(ctx) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	pages := a.docPages()
+
+	var files []string
+	write := func(name string, render func(w io.Writer) error) error {
+		file := filepath.Join(dir, name)
+		if IsDryRun(ctx) {
+			files = append(files, file)
+			return nil
+		}
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		if err := render(f); err != nil {
+			f.Close()
+			return err
+		}
+		files = append(files, file)
+		return f.Close()
+	}
+
+	for _, format := range formats {
+		for _, page := range pages {
+			var err error
+			switch format {
+			case docsFormatMan:
+				err = write(page.Name+".1", func(w io.Writer) error { return writeManPage(w, a.printer, page) })
+			case docsFormatMarkdown:
+				err = write(page.Name+".md", func(w io.Writer) error { return writeMarkdownPage(w, a.printer, page) })
+			}
+			if err != nil {
+				return files, err
+			}
+		}
+		if format == docsFormatHTML {
+			if err := write(pages[0].Name+".html", func(w io.Writer) error { return writeHTML(w, a.printer, pages) }); err != nil {
+				return files, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// docPages returns the pages of the root command and of all its available
+// subcommands, depth first.
+func (a *App) docPages() []docPage {
+	var pages []docPage
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		pages = append(pages, a.docPage(cmd))
+		for _, c := range cmd.Commands() {
+			if c.IsAvailableCommand() {
+				walk(c)
+			}
+		}
+	}
+	walk(a.cmd)
+	return pages
+}
+
+func (a *App) docPage(cmd *cobra.Command) docPage {
+	page := docPage{
+		Name:     docName(cmd),
+		Path:     cmd.CommandPath(),
+		Short:    docShort(cmd),
+		Long:     cmd.Long,
+		Usage:    cmd.UseLine(),
+		Examples: strings.TrimPrefix(strings.ReplaceAll(cmd.Example, "\n  ", "\n"), "  "),
+	}
+	if cmd.HasAvailableSubCommands() {
+		page.Usage += "\n" + cmd.CommandPath() + " [command]"
+	}
+	if page.Long == "" {
+		page.Long = page.Short
+	}
+	if msg := deprecation(a.printer, cmd); msg != "" {
+		page.Notices = append(page.Notices, a.printer.T("app.help.deprecated", msg))
+	}
+	if isExperimental(cmd) {
+		page.Notices = append(page.Notices, a.printer.T("app.help.experimental"))
+	}
+	if len(cmd.Aliases) > 0 {
+		page.Aliases = cmd.NameAndAliases()
+	}
+	if cmd.HasParent() {
+		page.Parent = &docLink{Name: docName(cmd.Parent()), Path: cmd.Parent().CommandPath(), Short: docShort(cmd.Parent())}
+	}
+	for _, c := range cmd.Commands() {
+		if c.IsAvailableCommand() {
+			page.Commands = append(page.Commands, docLink{Name: docName(c), Path: c.CommandPath(), Short: docShort(c)})
+		}
+	}
+
+	help, ok := a.helps[cmd]
+	if !ok {
+		help = a.builtinHelp(cmd)
+	}
+	for _, spec := range help.args {
+		page.Args = append(page.Args, [2]string{spec.usage(), a.printer.T(spec.Usage)})
+	}
+	keys := a.optionKeys(commandPath(cmd))
+	// the language is read before the options, see detectLanguage.
+	keys[langFlagName] = optionKey{envs: []string{envName([]string{a.commandName}, langFlagName)}}
+	if a.config != nil {
+		keys[langFlagName] = optionKey{
+			envs:   keys[langFlagName].envs,
+			config: globalConfigSection + "." + langFlagName,
+		}
+	}
+	for _, name := range help.sections.Order {
+		section := docSection{Title: docTitle(a.printer, cli.SectionTitle(a.printer, name))}
+		help.sections.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+			if f.Hidden {
+				return
+			}
+			term, usage := cli.FlagUsage(a.printer, f)
+			key := keys[f.Name]
+			section.Flags = append(section.Flags, docFlag{
+				Term:   strings.TrimSpace(term),
+				Usage:  usage,
+				Envs:   key.envs,
+				Config: key.config,
+			})
+		})
+		for _, group := range help.sections.SectionGroups(name) {
+			section.Groups = append(section.Groups, group.String())
+		}
+		if len(section.Flags) > 0 {
+			page.Sections = append(page.Sections, section)
+		}
+	}
+	return page
+}
+
+// builtinHelp returns the sections of the commands built by the app itself,
+// e.g. config, which are not made from a Command: their own flags and the
+// global section of the root command.
+func (a *App) builtinHelp(cmd *cobra.Command) commandHelp {
+	var help commandHelp
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != flagHelp {
+			help.sections.FlagSet(cmd.Name()).AddFlag(f)
+		}
+	})
+	if global, ok := a.helps[a.cmd].sections.FlagSets[globalFlagSetName]; ok {
+		help.sections.FlagSet(globalFlagSetName).AddFlagSet(global)
+	}
+	return help
+}
+
+// docShort returns the short description of cmd, the first line of the
+// description of the app for the root command.
+func docShort(cmd *cobra.Command) string {
+	if !cmd.HasParent() && cmd.Long != "" {
+		short, _, _ := strings.Cut(cmd.Long, "\n")
+		return short
+	}
+	return cmd.Short
+}
+
+// docName returns the command path of cmd joined by dashes.
+func docName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+// optionKey is where an option is read from besides its flag.
+type optionKey struct {
+	envs   []string
+	config string
+}
+
+// optionKeys returns the environment variables and the configuration keys of
+// the options of the command at path by flag name, following resolveOptions.
+func (a *App) optionKeys(path []string) map[string]optionKey {
+	keys := map[string]optionKey{}
+	for _, entry := range a.configEntries() {
+		if !slices.Equal(entry.path, path) {
+			continue
+		}
+		fss := a.pathFlags(entry.path, entry.options)
+		for _, name := range fss.Order {
+			// the global section of the root options is shared by all commands.
+			section := entry.path
+			if len(entry.path) == 0 && name == globalFlagSetName {
+				section = []string{globalConfigSection}
+			}
+			fss.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+				var key optionKey
+				if a.config != nil {
+					key.envs = append(key.envs, envName(append([]string{a.commandName}, path...), f.Name))
+					key.config = strings.Join(append(append([]string{}, section...), f.Name), ".")
+				}
+				if env, ok := cli.FlagEnv(f); ok {
+					key.envs = append(key.envs, env)
+				}
+				keys[f.Name] = key
+			})
+		}
+	}
+	return keys
+}
+
+// writeMarkdownPage writes page as a Markdown document in the language of p.
+func writeMarkdownPage(w io.Writer, p *i18n.Printer, page docPage) error {
+	titles := newDocTitles(p)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", page.Path, page.Long)
+	for _, notice := range page.Notices {
+		fmt.Fprintf(&b, "\n> %s\n", notice)
+	}
+	fmt.Fprintf(&b, "\n## %s\n\n```\n%s\n```\n", titles.Usage, page.Usage)
+	if len(page.Args) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n\n| %s | %s |\n| --- | --- |\n", titles.Arguments, titles.Argument, titles.Description)
+		for _, arg := range page.Args {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", arg[0], markdownCell(arg[1]))
+		}
+	}
+	if page.Aliases != "" {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", titles.Aliases, page.Aliases)
+	}
+	if page.Examples != "" {
+		fmt.Fprintf(&b, "\n## %s\n\n```\n%s\n```\n", titles.Examples, page.Examples)
+	}
+	for _, section := range page.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n| %s | %s | %s | %s |\n| --- | --- | --- | --- |\n",
+			section.Title, titles.Flag, titles.Description, titles.Env, titles.Config)
+		for _, f := range section.Flags {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", f.Term, markdownCell(f.Usage), markdownCodes(f.Envs...), markdownCodes(f.Config))
+		}
+		if len(section.Groups) > 0 {
+			fmt.Fprintln(&b)
+			for _, group := range section.Groups {
+				fmt.Fprintf(&b, "- %s\n", group)
+			}
+		}
+	}
+	if len(page.Commands) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n\n", titles.Commands)
+		for _, c := range page.Commands {
+			fmt.Fprintf(&b, "- [%s](%s.md) - %s\n", c.Path, c.Name, c.Short)
+		}
+	}
+	if page.Parent != nil {
+		fmt.Fprintf(&b, "\n## %s\n\n- [%s](%s.md) - %s\n", titles.SeeAlso, page.Parent.Path, page.Parent.Name, page.Parent.Short)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes s to be written in a table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", "<br>").Replace(s)
+}
+
+// markdownCodes writes the values as code spans, separated by line breaks.
+func markdownCodes(values ...string) string {
+	var codes []string
+	for _, v := range values {
+		if v != "" {
+			codes = append(codes, "`"+v+"`")
+		}
+	}
+	return strings.Join(codes, "<br>")
+}
+
+// writeManPage writes page as a man page of section 1 in the language of p.
+func writeManPage(w io.Writer, p *i18n.Printer, page docPage) error {
+	titles := newDocTitles(p)
+	var b strings.Builder
+	root, _, _ := strings.Cut(page.Path, " ")
+	fmt.Fprintf(&b, ".TH %q \"1\" \"\" %q %q\n", strings.ToUpper(page.Name), root, root)
+	fmt.Fprintf(&b, ".SH NAME\n%s \\- %s\n", roff(page.Name), roff(page.Short))
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s\n", strings.ReplaceAll(roff(page.Usage), "\n", "\n.br\n.B "))
+	fmt.Fprintf(&b, ".SH DESCRIPTION\n%s\n", roffText(page.Long))
+	for _, notice := range page.Notices {
+		fmt.Fprintf(&b, ".PP\n%s\n", roffText(notice))
+	}
+	if len(page.Args) > 0 {
+		fmt.Fprintf(&b, ".SH %s\n", manSection(titles.Arguments))
+		for _, arg := range page.Args {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roff(arg[0]), roffText(arg[1]))
+		}
+	}
+	if page.Aliases != "" {
+		fmt.Fprintf(&b, ".SH %s\n%s\n", manSection(titles.Aliases), roff(page.Aliases))
+	}
+	if page.Examples != "" {
+		fmt.Fprintf(&b, ".SH %s\n.PP\n.RS\n.nf\n%s\n.fi\n.RE\n", manSection(titles.Examples), roffText(page.Examples))
+	}
+	for _, section := range page.Sections {
+		fmt.Fprintf(&b, ".SH %s\n", manSection(section.Title))
+		for _, f := range section.Flags {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roff(f.Term), roffText(f.Usage))
+			if len(f.Envs) > 0 {
+				fmt.Fprintf(&b, ".br\n%s: %s\n", roff(titles.Env), roff(strings.Join(f.Envs, ", ")))
+			}
+			if f.Config != "" {
+				fmt.Fprintf(&b, ".br\n%s: %s\n", roff(titles.Config), roff(f.Config))
+			}
+		}
+		for _, group := range section.Groups {
+			fmt.Fprintf(&b, ".PP\n%s\n", roffText(group))
+		}
+	}
+	if len(page.Commands) > 0 {
+		fmt.Fprintf(&b, ".SH %s\n", manSection(titles.Commands))
+		for _, c := range page.Commands {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR(1)\n%s\n", roff(c.Name), roffText(c.Short))
+		}
+	}
+	if page.Parent != nil {
+		fmt.Fprintf(&b, ".SH %s\n\\fB%s\\fR(1)\n", manSection(titles.SeeAlso), roff(page.Parent.Name))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// manSection returns the quoted title of a man page section.
+func manSection(title string) string {
+	return fmt.Sprintf("%q", strings.ToUpper(title))
+}
+
+// roff escapes the backslashes and the dashes of s.
+func roff(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// roffText escapes s and the lines of s which would be read as requests.
+func roffText(s string) string {
+	lines := strings.Split(roff(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeHTML writes pages as a single HTML page with a table of contents in the
+// language of p.
+func writeHTML(w io.Writer, p *i18n.Printer, pages []docPage) error {
+	return htmlTemplate.Execute(w, struct {
+		Lang   string
+		Titles docTitles
+		Pages  []docPage
+	}{p.Language(), newDocTitles(p), pages})
+}
+
+var htmlTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{(index .Pages 0).Path}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; padding: 0 1em; }
+pre, code { font-family: monospace; }
+pre { background: #f5f5f5; padding: .5em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .25em .5em; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<nav>
+<ul>
+{{- range .Pages}}
+<li><a href="#{{.Name}}">{{.Path}}</a> - {{.Short}}</li>
+{{- end}}
+</ul>
+</nav>
+{{- $titles := .Titles}}
+{{- range .Pages}}
+<section id="{{.Name}}">
+<h2>{{.Path}}</h2>
+<p>{{.Long}}</p>
+{{- range .Notices}}
+<blockquote>{{.}}</blockquote>
+{{- end}}
+<h3>{{$titles.Usage}}</h3>
+<pre>{{.Usage}}</pre>
+{{- if .Args}}
+<h3>{{$titles.Arguments}}</h3>
+<table>
+<tr><th>{{$titles.Argument}}</th><th>{{$titles.Description}}</th></tr>
+{{- range .Args}}
+<tr><td><code>{{index . 0}}</code></td><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Aliases}}
+<h3>{{$titles.Aliases}}</h3>
+<p>{{.Aliases}}</p>
+{{- end}}
+{{- if .Examples}}
+<h3>{{$titles.Examples}}</h3>
+<pre>{{.Examples}}</pre>
+{{- end}}
+{{- range .Sections}}
+<h3>{{.Title}}</h3>
+<table>
+<tr><th>{{$titles.Flag}}</th><th>{{$titles.Description}}</th><th>{{$titles.Env}}</th><th>{{$titles.Config}}</th></tr>
+{{- range .Flags}}
+<tr><td><code>{{.Term}}</code></td><td>{{.Usage}}</td><td>{{range $i, $env := .Envs}}{{if $i}}<br>{{end}}<code>{{$env}}</code>{{end}}</td><td>{{if .Config}}<code>{{.Config}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Groups}}
+<ul>
+{{- range .Groups}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- if .Commands}}
+<h3>{{$titles.Commands}}</h3>
+<ul>
+{{- range .Commands}}
+<li><a href="#{{.Name}}">{{.Path}}</a> - {{.Short}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const dryRunFlagName = "dry-run"
+
+// Action is a side effect of a command which a dry run records instead of
+// performing it: an external command or the creation of files.
+type Action struct {
+	// Command is the command line of the external command, empty when the
+	// action only creates files.
+	Command []string `json:"command,omitempty"`
+	// Dir is the working directory of the command.
+	Dir string `json:"dir,omitempty"`
+	// Env are the variables set for the command in addition to the environment
+	// of the process, which is inherited.
+	Env []string `json:"env,omitempty"`
+	// Creates are the files and directories the action would create.
+	Creates []string `json:"creates,omitempty"`
+}
+
+// plan is the list of the actions recorded by a dry run.
+type plan struct {
+	mu      sync.Mutex
+	Actions []Action `json:"actions"`
+}
+
+type planContextKey struct{}
+
+// addDryRunFlag adds the --dry-run flag to the specified FlagSet object.
+func (a *App) addDryRunFlag(fs *pflag.FlagSet) {
+	fs.BoolVar(&a.dryRun, dryRunFlagName, a.dryRun, "")
+	a.setFlagUsage(fs, dryRunFlagName, "app.flag.dry_run", ExitCodeDryRunPending)
+}
+
+// IsDryRun reports whether the command in ctx is run with --dry-run, the run
+// func must then record its side effects with RecordAction instead of
+// performing them.
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(planContextKey{}).(*plan)
+	return ok
+}
+
+// RecordAction records an action of a dry run, it is ignored outside of a dry run.
+func RecordAction(ctx context.Context, action Action) {
+	p, ok := ctx.Value(planContextKey{}).(*plan)
+	if !ok {
+		return
+	}
+	if action.Command != nil && action.Dir == "" {
+		action.Dir, _ = os.Getwd()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Actions = append(p.Actions, action)
+}
+
+// Text returns the actions as the command lines to run.
+func (p *plan) Text() string {
+	return p.Localize(nil)
+}
+
+// Localize returns the text of the plan in the language of printer.
+func (p *plan) Localize(printer *i18n.Printer) string {
+	if len(p.Actions) == 0 {
+		return printer.T("app.dry_run.nothing") + "\n"
+	}
+	var b strings.Builder
+	for _, action := range p.Actions {
+		if len(action.Command) > 0 {
+			fmt.Fprintln(&b, printer.T("app.dry_run.run", runner.ShellJoin(action.Command)))
+			fmt.Fprintln(&b, printer.T("app.dry_run.dir", action.Dir))
+			env := printer.T("app.dry_run.inherited")
+			if len(action.Env) > 0 {
+				env += ", " + runner.ShellJoin(action.Env)
+			}
+			fmt.Fprintln(&b, printer.T("app.dry_run.env", env))
+		} else {
+			fmt.Fprintln(&b, printer.T("app.dry_run.write"))
+		}
+		for _, file := range action.Creates {
+			fmt.Fprintln(&b, printer.T("app.dry_run.creates", file))
+		}
+	}
+	return b.String()
+}
+
+// Table returns a row per action.
+func (p *plan) Table() ([]string, [][]string) {
+	return p.LocalizeTable(nil)
+}
+
+// LocalizeTable returns a row per action, the header is in the language of
+// printer.
+func (p *plan) LocalizeTable(printer *i18n.Printer) ([]string, [][]string) {
+	rows := make([][]string, 0, len(p.Actions))
+	for _, action := range p.Actions {
+		rows = append(rows, []string{runner.ShellJoin(action.Command), action.Dir, strings.Join(action.Env, " "),
+			strings.Join(action.Creates, ", ")})
+	}
+	return strings.Split(printer.T("app.dry_run.header"), "\t"), rows
+}
+
+// runWithPlan runs run with the middlewares in the run context of cmd. With
+// --dry-run the external commands run by the runner of the context and the
+// actions recorded by the command are printed as the result of the command, and
+// the exit code tells whether there is something to do.
+func (a *App) runWithPlan(cmd *cobra.Command, args []string, run RunContextFunc, options CliOptions, middlewares []Middleware) error {
+	ctx := runContext(cmd, args)
+	if !a.dryRun {
+		return chain(run, middlewares)(ctx, options)
+	}
+
+	p := &plan{Actions: []Action{}}
+	planCtx := context.WithValue(ctx, planContextKey{}, p)
+	dryRunner := runner.DryRun(runner.FromContext(ctx), func(c runner.Command) {
+		RecordAction(planCtx, Action{Command: c.Line(), Dir: c.Dir, Env: c.Env, Creates: c.Creates})
+	})
+	if err := chain(run, middlewares)(runner.NewContext(planCtx, dryRunner), options); err != nil {
+		return err
+	}
+	if err := printResult(cmd.OutOrStdout(), a.printer, outputFormat(cmd), p); err != nil {
+		return err
+	}
+	if len(p.Actions) > 0 {
+		return NewExitError(ExitCodeDryRunPending, "", nil)
+	}
+	return nil
+}
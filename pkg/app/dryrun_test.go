@@ -0,0 +1,71 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []app.Action
+		args    []string
+		code    int
+		want    string
+	}{
+		{
+			name: "pending actions",
+			actions: []app.Action{
+				{Command: []string{"pdf2docx", "convert", "a b.pdf", "a b.docx"}, Dir: "/work", Env: []string{"LANG=C"}, Creates: []string{"a b.docx"}},
+				{Creates: []string{"/home/.tool/require.json"}},
+			},
+			args: []string{"convert", "--dry-run"},
+			code: app.ExitCodeDryRunPending,
+			want: "Would run: pdf2docx convert \"a b.pdf\" \"a b.docx\"\n  dir:     /work\n  env:     inherited, LANG=C\n  creates: a b.docx\n" +
+				"Would write:\n  creates: /home/.tool/require.json\n",
+		},
+		{
+			name: "nothing to do",
+			args: []string{"convert", "--dry-run"},
+			code: app.ExitCodeOK,
+			want: "Nothing to do.\n",
+		},
+		{
+			name:    "json",
+			actions: []app.Action{{Command: []string{"you-get", "URL"}, Dir: "/work"}},
+			args:    []string{"convert", "--dry-run", "-o", "json"},
+			code:    app.ExitCodeDryRunPending,
+			want:    "{\n  \"actions\": [\n    {\n      \"command\": [\n        \"you-get\",\n        \"URL\"\n      ],\n      \"dir\": \"/work\"\n    }\n  ]\n}\n",
+		},
+		{
+			name:    "not a dry run",
+			actions: []app.Action{{Command: []string{"you-get", "URL"}}},
+			args:    []string{"convert"},
+			code:    app.ExitCodeOK,
+			want:    "done\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+				app.NewCommand("convert", "convert", app.WithCommandResultFunc(func(ctx context.Context, option app.CliOptions) (interface{}, error) {
+					for _, action := range tt.actions {
+						app.RecordAction(ctx, action)
+					}
+					return []string{"done"}, nil
+				}))))
+			var stdout, stderr bytes.Buffer
+			a.Command().SetOut(&stdout)
+			a.Command().SetErr(&stderr)
+
+			assert.Equal(t, tt.code, a.RunWithArgs(context.Background(), tt.args))
+			assert.Equal(t, tt.want, stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
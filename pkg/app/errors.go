@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// ExitCodeOK is the exit code of a successful run.
+	ExitCodeOK = 0
+	// ExitCodeError is the exit code used for errors without a specific code.
+	ExitCodeError = 1
+	// ExitCodeInvalidOptions is the exit code used when the flags or the options
+	// of the invoked command are invalid.
+	ExitCodeInvalidOptions = 2
+	// ExitCodeDryRunPending is the exit code of a run with --dry-run which
+	// would have run external commands or created files.
+	ExitCodeDryRunPending = 3
+	// ExitCodeInterrupted is the exit code used when the run is canceled by
+	// SIGINT or SIGTERM.
+	ExitCodeInterrupted = 130
+)
+
+// ExitError is an error carrying the exit code of the process and a hint which
+// tells the user how to fix it. An ExitError without Err only sets the exit code
+// and is not reported.
+type ExitError struct {
+	// Code is the exit code of the process.
+	Code int
+	// Hint is printed after the error message when it is not empty.
+	Hint string
+	// Err is the underlying error.
+	Err error
+}
+
+// NewExitError creates an ExitError wrapping err.
+func NewExitError(code int, hint string, err error) *ExitError {
+	return &ExitError{Code: code, Hint: hint, Err: err}
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Localize prints the error in the language of p, it implements
+// i18n.Localizer.
+func (e *ExitError) Localize(p *i18n.Printer) string {
+	if e.Err == nil {
+		return e.Error()
+	}
+	return p.Localize(e.Err).Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps an error returned by a command to the exit code of the process.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var invalid *InvalidOptionsError
+	if errors.As(err, &invalid) {
+		return ExitCodeInvalidOptions
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitCodeInterrupted
+	}
+
+	return ExitCodeError
+}
+
+// exitHint returns the hint attached to err, if any.
+func exitHint(err error) string {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Hint
+	}
+	return ""
+}
+
+// flagErrorFunc turns flag parse errors into ExitErrors pointing to the help of
+// the command.
+func (a *App) flagErrorFunc(cmd *cobra.Command, err error) error {
+	return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.hint.help", cmd.CommandPath()), err)
+}
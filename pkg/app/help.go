@@ -2,28 +2,40 @@ package app
 
 import (
 	"fmt"
+	"io"
+	"strings"
+
 	"github.com/fatih/color"
+	"github.com/lwm-galactic/tools/term"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"strings"
 )
 
 const (
 	flagHelp          = "help"
-	flagHelpShorthand = "H"
+	flagHelpShorthand = "h"
 )
 
-func helpCommand(name string) *cobra.Command {
-	return &cobra.Command{
-		Use:   "help [command]",
-		Short: "Help about any command.",
-		Long: `Help provides help for any command in the application.
-Simply type ` + name + ` help [path to command] for full details.`,
+// annotations of the cobra commands holding the metadata of a Command which
+// cobra does not model itself.
+const (
+	// annotationDeprecated holds the message key of the deprecation message.
+	annotationDeprecated   = "app.deprecated"
+	annotationExperimental = "app.experimental"
+	// annotationOptions marks the commands resolving options, the ones of the
+	// Commands and the root command, --print-config is rejected by the others.
+	annotationOptions = "app.options"
+)
 
+func (a *App) helpCommand(name string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "help [command]",
 		Run: func(c *cobra.Command, args []string) {
 			cmd, _, e := c.Root().Find(args)
 			if cmd == nil || e != nil {
-				c.Printf("Unknown help topic %#q\n", args)
+				c.Println(a.printer.T("app.help.unknown_topic", args))
 				_ = c.Root().Usage()
 			} else {
 				cmd.InitDefaultHelpFlag() // make possible 'help' flag to be shown
@@ -31,20 +43,157 @@ Simply type ` + name + ` help [path to command] for full details.`,
 			}
 		},
 	}
+	a.setText(&cmd.Short, "app.help.short")
+	a.setText(&cmd.Long, "app.help.long", name)
+	return cmd
 }
 
 // addHelpFlag adds flags for a specific application to the specified FlagSet object.
-func addHelpFlag(name string, fs *pflag.FlagSet) {
-	fs.BoolP(flagHelp, flagHelpShorthand, false, fmt.Sprintf("Help for %s.", name))
+func (a *App) addHelpFlag(name string, fs *pflag.FlagSet) {
+	fs.BoolP(flagHelp, flagHelpShorthand, false, "")
+	a.setFlagUsage(fs, flagHelp, "app.flag.help", name)
 }
 
 // addHelpCommandFlag adds flags for a specific command of application to the
 // specified FlagSet object.
-func addHelpCommandFlag(usage string, fs *pflag.FlagSet) {
-	fs.BoolP(
-		flagHelp,
-		flagHelpShorthand,
-		false,
-		fmt.Sprintf("Help for the %s command.", color.GreenString(strings.Split(usage, " ")[0])),
-	)
+func (a *App) addHelpCommandFlag(usage string, fs *pflag.FlagSet) {
+	fs.BoolP(flagHelp, flagHelpShorthand, false, "")
+	a.setFlagUsage(fs, flagHelp, "app.flag.help_command", color.GreenString(strings.Split(usage, " ")[0]))
+}
+
+// deprecation returns the deprecation message of cmd in the language of p,
+// empty if it is not deprecated.
+func deprecation(p *i18n.Printer, cmd *cobra.Command) string {
+	if key := cmd.Annotations[annotationDeprecated]; key != "" {
+		return p.T(key)
+	}
+	return ""
+}
+
+// isExperimental reports whether cmd is marked as experimental.
+func isExperimental(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[annotationExperimental]
+	return ok
+}
+
+// warnDeprecated prints the deprecation warning of cmd, if any, to its error output.
+func (a *App) warnDeprecated(cmd *cobra.Command) {
+	if msg := deprecation(a.printer, cmd); msg != "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), a.printer.T("app.deprecated.warning", cmd.Name(), msg))
+	}
+}
+
+// addCommandGroups registers the groups of the subcommands of cmd, cobra
+// requires the group of a command to exist on its parent.
+func (a *App) addCommandGroups(cmd *cobra.Command) {
+	for _, c := range cmd.Commands() {
+		if c.GroupID != "" && !cmd.ContainsGroup(c.GroupID) {
+			group := &cobra.Group{ID: c.GroupID}
+			a.setText(&group.Title, c.GroupID)
+			cmd.AddGroup(group)
+		}
+	}
+}
+
+// commandHelp is what the help of a command shows besides the cobra command
+// itself, it is kept to generate the docs.
+type commandHelp struct {
+	sections cli.NamedFlagSets
+	args     []Arg
+}
+
+// terminal beautify
+func (a *App) addCmdTemplate(cmd *cobra.Command, namedFlagSets cli.NamedFlagSets, specs ...Arg) {
+	a.helps[cmd] = commandHelp{sections: namedFlagSets, args: specs}
+	usageFmt := "%s\n  %s\n"
+	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
+		w := cmd.OutOrStderr()
+		fmt.Fprintf(w, usageFmt, cli.Heading(a.printer.T("app.help.usage")), cmd.UseLine())
+		printSubCommands(w, a.printer, cmd)
+		cli.PrintLocalizedSections(w, a.printer, namedFlagSets, terminalWidth(w))
+
+		return nil
+	})
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		w := cmd.OutOrStdout()
+		long := cmd.Long
+		if long == "" {
+			long = cmd.Short
+		}
+		cols := terminalWidth(w)
+		fmt.Fprintf(w, "%s\n\n", long)
+		printNotices(w, a.printer, cmd)
+		fmt.Fprintf(w, usageFmt, cli.Heading(a.printer.T("app.help.usage")), cmd.UseLine())
+		printSubCommands(w, a.printer, cmd)
+		printArguments(w, a.printer, specs, cols)
+		printAliases(w, a.printer, cmd)
+		printExamples(w, a.printer, cmd)
+		cli.PrintLocalizedSections(w, a.printer, namedFlagSets, cols)
+	})
+}
+
+// terminalWidth returns the width of the terminal w writes to, zero when w is
+// not a terminal so that the help is not wrapped.
+func terminalWidth(w io.Writer) int {
+	cols, _, _ := term.TerminalSize(w)
+	return cols
+}
+
+// printNotices prints the deprecation and experimental notices of cmd.
+func printNotices(w io.Writer, p *i18n.Printer, cmd *cobra.Command) {
+	if msg := deprecation(p, cmd); msg != "" {
+		fmt.Fprintf(w, "%s\n\n", p.T("app.help.deprecated", msg))
+	}
+	if isExperimental(cmd) {
+		fmt.Fprintf(w, "%s\n\n", p.T("app.help.experimental"))
+	}
+}
+
+func printAliases(w io.Writer, p *i18n.Printer, cmd *cobra.Command) {
+	if len(cmd.Aliases) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n  %s\n", cli.Heading(p.T("app.help.aliases")), cmd.NameAndAliases())
+}
+
+func printExamples(w io.Writer, p *i18n.Printer, cmd *cobra.Command) {
+	if !cmd.HasExample() {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n%s\n", cli.Heading(p.T("app.help.examples")), cmd.Example)
+}
+
+// printSubCommands prints the usage line and the list of the available
+// subcommands of cmd, if any. Hidden and deprecated commands are left out,
+// grouped commands are listed below the title of their group.
+func printSubCommands(w io.Writer, p *i18n.Printer, cmd *cobra.Command) {
+	if !cmd.HasAvailableSubCommands() {
+		return
+	}
+	fmt.Fprintf(w, "  %s [command]\n", cmd.CommandPath())
+
+	printGroup := func(title, groupID string) {
+		var listed []*cobra.Command
+		for _, c := range cmd.Commands() {
+			if c.IsAvailableCommand() && deprecation(p, c) == "" && c.GroupID == groupID {
+				listed = append(listed, c)
+			}
+		}
+		if len(listed) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s\n", cli.Heading(title))
+		for _, c := range listed {
+			short := c.Short
+			if isExperimental(c) {
+				short += p.T("app.help.experimental_suffix")
+			}
+			fmt.Fprintf(w, "  %-*s %s\n", c.NamePadding(), c.Name(), short)
+		}
+	}
+	printGroup(p.T("app.help.commands"), "")
+	for _, group := range cmd.Groups() {
+		printGroup(group.Title+":", group.ID)
+	}
+	fmt.Fprintf(w, "\n%s\n", p.T("app.help.more", cmd.CommandPath()))
 }
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const langFlagName = "lang"
+
+// addLangFlag adds the --lang flag to the specified FlagSet object.
+func (a *App) addLangFlag(fs *pflag.FlagSet) {
+	fs.StringVar(&a.lang, langFlagName, "", "")
+	a.setFlagUsage(fs, langFlagName, "app.flag.lang", strings.Join(i18n.Languages(), ", "))
+	cli.MarkValuesCompletion(fs, langFlagName, i18n.Languages()...)
+}
+
+// checkLanguage validates --lang, the language itself is selected by localize
+// before the flags are parsed.
+func (a *App) checkLanguage(cmd *cobra.Command, args []string) error {
+	if _, ok := i18n.Match(a.lang); a.lang != "" && !ok {
+		return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.hint.help", cmd.CommandPath()),
+			i18n.Errorf("i18n.unsupported", a.lang, strings.Join(i18n.Languages(), ", ")))
+	}
+	return nil
+}
+
+// detectLanguage returns the language of the messages of a run with args, in order
+// the first supported one of: --lang, the <COMMAND>_LANG environment variable,
+// the lang key of the configuration file and the locale of the system.
+func (a *App) detectLanguage(args []string) string {
+	tags := []string{
+		flagValue(args, langFlagName, ""),
+		os.Getenv(envName([]string{a.commandName}, langFlagName)),
+	}
+	if !a.noConfig {
+		c := newConfig(a.commandName)
+		c.file = flagValue(args, configFlagName, "c")
+		// a configuration file which can not be read is reported by the run.
+		if c.load() == nil {
+			if v, err := c.commandViper(nil); err == nil {
+				tags = append(tags, v.GetString(langFlagName))
+			}
+		}
+	}
+	return i18n.Detect(append(tags, i18n.SystemLocale())...)
+}
+
+// message is a text of the command tree, a message key and its arguments, and
+// the text in the language it was last translated to.
+type message struct {
+	key  string
+	args []interface{}
+	text string
+}
+
+// setText sets the text at s, a field of the command tree, to the message of
+// key in the language of the printer of the app, and records it so that
+// localize translates it again when a run is in another language.
+func (a *App) setText(s *string, key string, args ...interface{}) {
+	*s = a.printer.T(key, args...)
+	a.texts[s] = message{key: key, args: args, text: *s}
+}
+
+// setFlagUsage sets the usage of the flag name of fs, see setText.
+func (a *App) setFlagUsage(fs *pflag.FlagSet, name, key string, args ...interface{}) {
+	a.setText(&fs.Lookup(name).Usage, key, args...)
+}
+
+// localize selects the language of the messages of a run with args. The
+// language belongs to the app, not to the process, so that apps can run in
+// parallel in different languages. The texts of the command tree set by
+// setText are translated in place, the ones changed since, e.g. by the program
+// embedding the app, are kept.
+func (a *App) localize(args []string) {
+	a.printer = i18n.NewPrinter(a.detectLanguage(args))
+	a.translateCommand(a.cmd)
+}
+
+// translateCommand translates the texts of cmd and of its subcommands in the
+// language of the printer of the app.
+func (a *App) translateCommand(cmd *cobra.Command) {
+	a.translate(&cmd.Short)
+	a.translate(&cmd.Long)
+	for _, group := range cmd.Groups() {
+		a.translate(&group.Title)
+	}
+	for _, fs := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+		fs.VisitAll(func(f *pflag.Flag) {
+			a.translate(&f.Usage)
+			a.translate(&f.Deprecated)
+		})
+		cli.LocalizeFlags(fs, a.printer)
+	}
+	for _, sub := range cmd.Commands() {
+		a.translateCommand(sub)
+	}
+}
+
+// translate translates the text at s again when it was set by setText and is
+// not changed since.
+func (a *App) translate(s *string) {
+	if msg, ok := a.texts[s]; ok && *s == msg.text {
+		a.setText(s, msg.key, msg.args...)
+	}
+}
+
+// recordFlagTexts records the usage and the deprecation message of the flags
+// of cmd and of its subcommands built from struct tags, they are translated
+// by cli.StructFlags.
+func (a *App) recordFlagTexts(cmd *cobra.Command) {
+	for _, fs := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+		fs.VisitAll(func(f *pflag.Flag) {
+			usage, deprecated, ok := cli.FlagMessageKeys(f)
+			if !ok {
+				return
+			}
+			if usage != "" {
+				a.texts[&f.Usage] = message{key: usage, text: f.Usage}
+			}
+			if deprecated != "" {
+				a.texts[&f.Deprecated] = message{key: deprecated, text: f.Deprecated}
+			}
+		})
+	}
+	for _, sub := range cmd.Commands() {
+		a.recordFlagTexts(sub)
+	}
+}
+
+// flagValue returns the last value of the flag name given in args, which are
+// not parsed yet, empty when it is not given.
+func flagValue(args []string, name, shorthand string) string {
+	prefixes := []string{"--" + name}
+	if shorthand != "" {
+		prefixes = append(prefixes, "-"+shorthand)
+	}
+
+	value := ""
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, prefix := range prefixes {
+			switch {
+			case arg == prefix && i+1 < len(args):
+				value = args[i+1]
+			case strings.HasPrefix(arg, prefix+"="):
+				value = strings.TrimPrefix(arg, prefix+"=")
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,180 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain runs the tests in the Fallback language, whatever the locale of the
+// machine running them.
+func TestMain(m *testing.M) {
+	os.Setenv("LC_ALL", "C")
+	os.Exit(m.Run())
+}
+
+func Test_Language(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "default", args: []string{"sub", "--help"}, stdout: "Usage:"},
+		{name: "flag", args: []string{"sub", "--lang", "zh-CN", "--help"}, stdout: "用法:"},
+		{name: "flag value", args: []string{"--lang=zh_CN.UTF-8", "sub", "--help"}, stdout: "Sub 选项:"},
+		{name: "locale", env: map[string]string{"LC_ALL": "zh_CN.UTF-8"}, args: []string{"sub", "--help"}, stdout: "用法:"},
+		{name: "env", env: map[string]string{"TEST_LANG": "zh"}, args: []string{"sub", "--help"}, stdout: "可用命令:"},
+		{name: "config", config: "global:\n  lang: zh-CN\n", args: []string{"sub", "--help"}, stdout: "用法:"},
+		{name: "flag over config", config: "lang: zh-CN\n", args: []string{"sub", "--lang", "en", "--help"}, stdout: "Usage:"},
+		{
+			name:   "error",
+			args:   []string{"sub", "--lang", "zh-CN", "--unknown"},
+			code:   app.ExitCodeInvalidOptions,
+			stderr: "错误: unknown flag: --unknown\n提示: 运行 'test sub --help' 查看用法。",
+		},
+		{
+			name:   "unsupported",
+			args:   []string{"sub", "--lang", "fr"},
+			code:   app.ExitCodeInvalidOptions,
+			stderr: `unsupported language "fr", must be one of en-US, zh-CN`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			args := tt.args
+			if tt.config != "" {
+				args = append([]string{"--config", writeConfig(t, tt.config)}, args...)
+			}
+
+			run := app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })
+			sub := app.NewCommand("sub", "sub command", app.WithCommandOptions(&subOptions{}), run,
+				app.WithSubCommands(app.NewCommand("leaf", "leaf command", run)))
+			a := app.NewApp("test", "test", app.WithCommands(sub))
+			var stdout, stderr bytes.Buffer
+			a.Command().SetOut(&stdout)
+			a.Command().SetErr(&stderr)
+
+			assert.Equal(t, tt.code, a.RunWithArgs(context.Background(), args))
+			assert.Contains(t, stdout.String(), tt.stdout)
+			assert.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
+
+type langOptions struct {
+	Format cli.Enum `mapstructure:"format" usage:"cmd.pdf2docx.flag.gui"`
+}
+
+func (o *langOptions) Validate() []error { return nil }
+
+func Test_LanguagePerApp(t *testing.T) {
+	newApp := func() (*app.App, *bytes.Buffer, *bytes.Buffer) {
+		run := app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })
+		sub := app.NewCommand("sub", "cmd.download.short", run,
+			app.WithCommandOptions(&langOptions{Format: cli.Enum{Values: []string{"json", "yaml"}}}))
+		a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(sub))
+		var stdout, stderr bytes.Buffer
+		a.Command().SetOut(&stdout)
+		a.Command().SetErr(&stderr)
+		return a, &stdout, &stderr
+	}
+
+	tests := []struct {
+		lang   string
+		stdout []string
+		stderr string
+	}{
+		{lang: "en", stdout: []string{"Usage:", "Download videos from bilibili or youtube", "Open the graphical interface"}, stderr: "must be one of json, yaml"},
+		{lang: "zh", stdout: []string{"用法:", "用于下载 bilibili | youtube 的视频工具", "是否使用图形化界面操作"}, stderr: "可选值为 json, yaml"},
+	}
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		a, stdout, stderr := newApp()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				stdout.Reset()
+				stderr.Reset()
+				help := a.RunWithArgs(context.Background(), []string{"--lang", tt.lang, "sub", "--help"})
+				parse := a.RunWithArgs(context.Background(), []string{"--lang", tt.lang, "sub", "--format", "xml"})
+				if !assert.Equal(t, app.ExitCodeOK, help) || !assert.Equal(t, app.ExitCodeInvalidOptions, parse) {
+					return
+				}
+				for _, want := range tt.stdout {
+					assert.Contains(t, stdout.String(), want, fmt.Sprintf("%s run %d", tt.lang, i))
+				}
+				assert.Contains(t, stderr.String(), tt.stderr, fmt.Sprintf("%s run %d", tt.lang, i))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, i18n.Fallback, i18n.Language())
+}
+
+func Test_LanguageKeepsCommand(t *testing.T) {
+	run := app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("sub", "sub command", run),
+		app.NewCommand("download", "cmd.download.short", run),
+	))
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+	// the program embedding the app customizes the tree it built.
+	sub, _, err := a.Command().Find([]string{"sub"})
+	assert.Nil(t, err)
+	sub.Short = "custom description"
+	a.Command().Flags().Lookup("lang").Usage = "custom usage"
+
+	for _, lang := range []string{"zh-CN", "en", "zh-CN"} {
+		stdout.Reset()
+		assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"--lang", lang, "--help"}))
+		assert.Contains(t, stdout.String(), "custom description")
+		assert.Contains(t, stdout.String(), "custom usage")
+		download, _, err := a.Command().Find([]string{"download"})
+		assert.Nil(t, err)
+		assert.Equal(t, i18n.NewPrinter(lang).T("cmd.download.short"), download.Short)
+	}
+	assert.Contains(t, stdout.String(), "用于下载 bilibili | youtube 的视频工具")
+	found, _, err := a.Command().Find([]string{"sub"})
+	assert.Nil(t, err)
+	assert.Same(t, sub, found)
+}
+
+func Test_LanguageSameText(t *testing.T) {
+	run := app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })
+	// the description of plain is not a message key but the English text of one.
+	text := i18n.NewPrinter("en").T("cmd.download.short")
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+		app.NewCommand("download", "cmd.download.short", run),
+		app.NewCommand("plain", text, run),
+	))
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"--lang", "zh-CN", "list", "--output", "table"}))
+	download, _, err := a.Command().Find([]string{"download"})
+	assert.Nil(t, err)
+	plain, _, err := a.Command().Find([]string{"plain"})
+	assert.Nil(t, err)
+	assert.Equal(t, i18n.NewPrinter("zh-CN").T("cmd.download.short"), download.Short)
+	assert.Equal(t, text, plain.Short)
+	assert.Contains(t, stdout.String(), "命令")
+	assert.NotContains(t, stdout.String(), "COMMAND")
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+const originBuiltin = "built-in"
+
+// commandNode is a command of the tree printed by the list command.
+type commandNode struct {
+	Name         string   `json:"name"`
+	Short        string   `json:"short,omitempty"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Group        string   `json:"group,omitempty"`
+	Deprecated   string   `json:"deprecated,omitempty"`
+	Experimental bool     `json:"experimental,omitempty"`
+	// Origin is built-in or the path of the plugin.
+	Origin   string         `json:"origin"`
+	Commands []*commandNode `json:"commands,omitempty"`
+}
+
+// commandTree returns the available subcommands of cmd and their subcommands,
+// hidden commands are left out. The deprecation messages are in the language
+// of p.
+func commandTree(p *i18n.Printer, cmd *cobra.Command) []*commandNode {
+	var nodes []*commandNode
+	for _, c := range cmd.Commands() {
+		if !c.IsAvailableCommand() {
+			continue
+		}
+		nodes = append(nodes, &commandNode{
+			Name:         c.Name(),
+			Short:        c.Short,
+			Aliases:      c.Aliases,
+			Group:        c.GroupID,
+			Deprecated:   deprecation(p, c),
+			Experimental: isExperimental(c),
+			Origin:       originBuiltin,
+			Commands:     commandTree(p, c),
+		})
+	}
+	return nodes
+}
+
+// printCommandTree prints nodes as an indented tree in the language of p.
+func printCommandTree(w io.Writer, p *i18n.Printer, nodes []*commandNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		desc := node.Short
+		if node.Origin != originBuiltin {
+			desc = p.T("app.list.plugin", node.Origin)
+		}
+		fmt.Fprintf(w, "%s%s%s: %s\n", indent, branch, node.label(p), desc)
+		printCommandTree(w, p, node.Commands, indent+next)
+	}
+}
+
+// label returns the name of the node followed by its metadata, e.g.
+// `download (aliases: dl) [experimental]`.
+func (node *commandNode) label(p *i18n.Printer) string {
+	label := node.Name
+	if len(node.Aliases) > 0 {
+		label += p.T("app.list.aliases", strings.Join(node.Aliases, ", "))
+	}
+	if node.Group != "" {
+		label += fmt.Sprintf(" [%s]", node.Group)
+	}
+	if node.Experimental {
+		label += p.T("app.list.experimental")
+	}
+	if node.Deprecated != "" {
+		label += p.T("app.list.deprecated", node.Deprecated)
+	}
+	return label
+}
+
+// commandList is the result of the list command.
+type commandList struct {
+	root  string
+	nodes []*commandNode
+}
+
+// MarshalJSON encodes the list as the array of the commands.
+func (l commandList) MarshalJSON() ([]byte, error) {
+	if l.nodes == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l.nodes)
+}
+
+// Text returns the command tree.
+func (l commandList) Text() string {
+	return l.Localize(nil)
+}
+
+// Localize returns the command tree in the language of p.
+func (l commandList) Localize(p *i18n.Printer) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, p.T("app.list.title"))
+	printCommandTree(&b, p, l.nodes, "")
+	fmt.Fprintln(&b, p.T("app.list.more", l.root))
+	return b.String()
+}
+
+// Table returns a row per command with its full path.
+func (l commandList) Table() ([]string, [][]string) {
+	return l.LocalizeTable(nil)
+}
+
+// LocalizeTable returns a row per command with its full path, the header is
+// in the language of p.
+func (l commandList) LocalizeTable(p *i18n.Printer) ([]string, [][]string) {
+	var rows [][]string
+	var walk func(parent string, nodes []*commandNode)
+	walk = func(parent string, nodes []*commandNode) {
+		for _, node := range nodes {
+			name := strings.TrimSpace(parent + " " + node.Name)
+			rows = append(rows, []string{name, strings.Join(node.Aliases, ","), node.Origin, node.Short})
+			walk(name, node.Commands)
+		}
+	}
+	walk("", l.nodes)
+	return strings.Split(p.T("app.list.header"), "\t"), rows
+}
+
+func (a *App) addListCmd() *cobra.Command {
+	var asJSON bool
+	// 创建 list 子命令
+	cmd := &cobra.Command{
+		Use:  "list",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nodes := commandTree(a.printer, cmd.Root())
+			if a.plugins {
+				for _, p := range a.discoverPlugins() {
+					if p.shadowedBy == "" && !a.isBuiltin(p.name) {
+						nodes = append(nodes, &commandNode{Name: p.name, Origin: p.path})
+					}
+				}
+			}
+
+			format := outputFormat(cmd)
+			if asJSON {
+				format = OutputJSON
+			}
+			return printResult(cmd.OutOrStdout(), a.printer, format, commandList{root: cmd.Root().Name(), nodes: nodes})
+		},
+	}
+	a.setText(&cmd.Short, "app.list.short")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "")
+	a.setFlagUsage(cmd.Flags(), "json", "app.list.flag.json")
+	return cmd
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/lwm-galactic/utool/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+// Middleware wraps the run func of a command, e.g. to time it or to check its
+// dependencies. A middleware short-circuits the command by returning without
+// calling next.
+type Middleware func(next RunContextFunc) RunContextFunc
+
+type commandContextKey struct{}
+
+// WithMiddleware adds middlewares wrapping the run func of the app and of all
+// its commands. The first middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(a *App) {
+		a.middlewares = append(a.middlewares, middlewares...)
+	}
+}
+
+// WithCommandMiddleware adds middlewares wrapping the run func of the command
+// and of its subcommands, inside the middlewares of the app.
+func WithCommandMiddleware(middlewares ...Middleware) CommandOption {
+	return func(c *Command) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// chain wraps run with middlewares, the first middleware is the outermost one.
+func chain(run RunContextFunc, middlewares []Middleware) RunContextFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		run = middlewares[i](run)
+	}
+	return run
+}
+
+// CommandFromContext returns the command being run, it is set in the context
+// passed to the middlewares and run funcs.
+func CommandFromContext(ctx context.Context) *cobra.Command {
+	cmd, _ := ctx.Value(commandContextKey{}).(*cobra.Command)
+	return cmd
+}
+
+// commandName returns the path of the command in ctx, e.g. `tool pdf2docx convert`.
+func commandName(ctx context.Context) string {
+	if cmd := CommandFromContext(ctx); cmd != nil {
+		return cmd.CommandPath()
+	}
+	return "command"
+}
+
+// PreRun returns a middleware calling hook before the run func, the command is
+// not run when hook fails.
+func PreRun(hook RunContextFunc) Middleware {
+	return func(next RunContextFunc) RunContextFunc {
+		return func(ctx context.Context, option CliOptions) error {
+			if err := hook(ctx, option); err != nil {
+				return err
+			}
+			return next(ctx, option)
+		}
+	}
+}
+
+// PostRun returns a middleware calling hook after the run func, even if it
+// failed. hook gets the error of the run func and returns the error of the command.
+func PostRun(hook func(ctx context.Context, option CliOptions, err error) error) Middleware {
+	return func(next RunContextFunc) RunContextFunc {
+		return func(ctx context.Context, option CliOptions) error {
+			return hook(ctx, option, next(ctx, option))
+		}
+	}
+}
+
+// Recover returns a middleware turning a panic of the run func into an error,
+// the stack is logged.
+func Recover() Middleware {
+	return func(next RunContextFunc) RunContextFunc {
+		return func(ctx context.Context, option CliOptions) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("%s panicked: %v\n%s", commandName(ctx), r, debug.Stack())
+					err = i18n.Errorf("app.middleware.panicked", commandName(ctx), r)
+				}
+			}()
+			return next(ctx, option)
+		}
+	}
+}
+
+// Timing returns a middleware logging how long the run func took.
+func Timing() Middleware {
+	return func(next RunContextFunc) RunContextFunc {
+		return func(ctx context.Context, option CliOptions) error {
+			start := time.Now()
+			err := next(ctx, option)
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if err != nil {
+				log.Infof("%v %s", progressMessage, i18n.FromContext(ctx).T("app.middleware.failed_after", commandName(ctx), elapsed))
+				return err
+			}
+			log.Infof("%v %s", progressMessage, i18n.FromContext(ctx).T("app.middleware.finished_in", commandName(ctx), elapsed))
+			return nil
+		}
+	}
+}
+
+// RequireCommands returns a middleware checking that the external commands
+// names are found on PATH before the run func is called. A dry run only warns
+// about the missing commands.
+func RequireCommands(names ...string) Middleware {
+	return func(next RunContextFunc) RunContextFunc {
+		return func(ctx context.Context, option CliOptions) error {
+			var missing []string
+			for _, name := range names {
+				if _, err := runner.FromContext(ctx).LookPath(name); err != nil {
+					missing = append(missing, name)
+				}
+			}
+			if len(missing) > 0 {
+				p := i18n.FromContext(ctx)
+				hint := p.T("app.middleware.install_hint", strings.Join(missing, ", "))
+				if cmd := CommandFromContext(ctx); cmd != nil {
+					if initCmd, _, err := cmd.Root().Find([]string{"init"}); err == nil && initCmd != cmd.Root() {
+						hint = p.T("app.middleware.init_hint", initCmd.CommandPath(), strings.Join(missing, ", "))
+					}
+				}
+				err := p.Errorf("app.middleware.missing", commandName(ctx), strings.Join(missing, ", "))
+				if IsDryRun(ctx) {
+					log.Warnf("%v, %s", err, hint)
+					return next(ctx, option)
+				}
+				return NewExitError(ExitCodeError, hint, err)
+			}
+			return next(ctx, option)
+		}
+	}
+}
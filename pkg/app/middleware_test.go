@@ -0,0 +1,96 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+// record returns a middleware appending name to calls before and after next.
+func record(calls *[]string, name string) app.Middleware {
+	return func(next app.RunContextFunc) app.RunContextFunc {
+		return func(ctx context.Context, option app.CliOptions) error {
+			*calls = append(*calls, name)
+			err := next(ctx, option)
+			*calls = append(*calls, "/"+name)
+			return err
+		}
+	}
+}
+
+func Test_MiddlewareOrder(t *testing.T) {
+	var calls []string
+	leaf := app.NewCommand("convert", "convert", app.WithCommandMiddleware(record(&calls, "convert")),
+		app.WithCommandRunContextFunc(func(ctx context.Context, option app.CliOptions) error {
+			assert.Equal(t, "test pdf2docx convert", app.CommandFromContext(ctx).CommandPath())
+			calls = append(calls, "run")
+			return nil
+		}))
+	a := app.NewApp("test", "test", app.WithNoConfig(),
+		app.WithMiddleware(record(&calls, "app")),
+		app.WithCommands(app.NewCommand("pdf2docx", "pdf2docx",
+			app.WithCommandMiddleware(record(&calls, "pdf2docx")), app.WithSubCommands(leaf))),
+	)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"pdf2docx", "convert"}))
+	assert.Equal(t, []string{"app", "pdf2docx", "convert", "run", "/convert", "/pdf2docx", "/app"}, calls)
+}
+
+func Test_MiddlewareShortCircuit(t *testing.T) {
+	ran := false
+	run := app.WithCommandRunFunc(func(option app.CliOptions) error {
+		ran = true
+		return nil
+	})
+	tests := []struct {
+		name       string
+		middleware app.Middleware
+		code       int
+	}{
+		{
+			name: "pre run error",
+			middleware: app.PreRun(func(ctx context.Context, option app.CliOptions) error {
+				return errors.New("not ready")
+			}),
+			code: app.ExitCodeError,
+		},
+		{
+			name:       "missing dependency",
+			middleware: app.RequireCommands("utool-test-missing-dependency"),
+			code:       app.ExitCodeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = false
+			a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+				app.NewCommand("download", "download", run, app.WithCommandMiddleware(tt.middleware))))
+			a.Command().SetErr(io.Discard)
+
+			assert.Equal(t, tt.code, a.RunWithArgs(context.Background(), []string{"download"}))
+			assert.False(t, ran)
+		})
+	}
+}
+
+func Test_MiddlewareRecoverAndPostRun(t *testing.T) {
+	var postErr error
+	a := app.NewApp("test", "test", app.WithNoConfig(),
+		app.WithMiddleware(app.PostRun(func(ctx context.Context, option app.CliOptions, err error) error {
+			postErr = err
+			return err
+		}), app.Recover(), app.Timing()),
+		app.WithCommands(app.NewCommand("crash", "crash", app.WithCommandRunFunc(func(option app.CliOptions) error {
+			panic("boom")
+		}))),
+	)
+	a.Command().SetErr(io.Discard)
+
+	assert.Equal(t, app.ExitCodeError, a.RunWithArgs(context.Background(), []string{"crash"}))
+	assert.EqualError(t, postErr, "test crash panicked: boom")
+}
@@ -1,13 +1,28 @@
 package app
 
-import "github.com/lwm-galactic/utool/pkg/cli"
+import (
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/spf13/cobra"
+)
 
 // CliOptions configuration options for reading parameters from the command line.
+// The options are a pointer to a struct, its flags are built from the tags of
+// its fields by cli.StructFlags unless it implements FlagsOptions, and the tags
+// are checked by cli.ValidateStruct. Validate returns the errors of the checks
+// the tags can not express, they are reported with the errors of the tags.
 type CliOptions interface {
-	Flags() (fss cli.NamedFlagSets)
 	Validate() []error
 }
 
+// FlagsOptions abstracts options which build their flags by hand.
+type FlagsOptions interface {
+	Flags() (fss cli.NamedFlagSets)
+}
+
 // CompletableOptions abstracts options which can be completed.
 type CompletableOptions interface {
 	Complete() error
@@ -17,3 +32,96 @@ type CompletableOptions interface {
 type PrintableOptions interface {
 	String() string
 }
+
+// CompletionOptions abstracts options which complete the values of their flags
+// dynamically, e.g. depending on the value of other flags. The functions are
+// keyed by flag name.
+type CompletionOptions interface {
+	FlagCompletions() map[string]cobra.CompletionFunc
+}
+
+// InvalidOptionsError aggregates the errors returned by CliOptions.Validate.
+type InvalidOptionsError struct {
+	Errors []error
+}
+
+// Error prints every validation error on its own line.
+func (e *InvalidOptionsError) Error() string {
+	return e.Localize(nil)
+}
+
+// Localize prints the errors in the language of p, it implements
+// i18n.Localizer.
+func (e *InvalidOptionsError) Localize(p *i18n.Printer) string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, p.T("app.options.invalid"))
+	for _, err := range e.Errors {
+		lines = append(lines, "  - "+p.Localize(err).Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// optionFlags returns the flag sets of options, the flags built from the tags
+// of options are added to the section named after the command.
+func optionFlags(options CliOptions, command string) cli.NamedFlagSets {
+	if flagsOptions, ok := options.(FlagsOptions); ok {
+		return flagsOptions.Flags()
+	}
+	return cli.StructFlags(options, command)
+}
+
+// pathFlags returns the flag sets of the options of the command at path.
+func (a *App) pathFlags(path []string, options CliOptions) cli.NamedFlagSets {
+	if len(path) == 0 {
+		return optionFlags(options, a.commandName)
+	}
+	return optionFlags(options, path[len(path)-1])
+}
+
+// checkFlagGroups enforces the flag groups of fss on the command at path. A
+// flag is set when its option is set, see optionSet, or when positional args
+// with its name are given, e.g. the <url> args stand for --url.
+func (a *App) checkFlagGroups(cmd *cobra.Command, path []string, fss cli.NamedFlagSets, specs []Arg, args []string) error {
+	errs := fss.CheckGroups(func(name string) bool {
+		for i := 0; len(specs) > 0 && i < len(args); i++ {
+			if specs[min(i, len(specs)-1)].Name == name {
+				return true
+			}
+		}
+		return a.optionSet(cmd, path, name)
+	})
+	if len(errs) != 0 {
+		return &InvalidOptionsError{Errors: errs}
+	}
+	return nil
+}
+
+// applyOptionRules runs the option lifecycle shared by App and Command:
+// complete, validate, then print the options unless the app is silent.
+func (a *App) applyOptionRules(options CliOptions) error {
+	if options == nil {
+		return nil
+	}
+
+	if completableOptions, ok := options.(CompletableOptions); ok {
+		if err := completableOptions.Complete(); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, err := range append(cli.ValidateStruct(options), options.Validate()...) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return &InvalidOptionsError{Errors: errs}
+	}
+
+	if printableOptions, ok := options.(PrintableOptions); ok && !a.silence {
+		log.Infof("%v %s", progressMessage, a.printer.T("app.log.config", printableOptions.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const outputFlagName = "output"
+
+// formats of the results selected by --output.
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+)
+
+var outputFormats = []string{OutputText, OutputJSON, OutputYAML, OutputTable}
+
+// ResultFunc defines the startup callback function of a command returning a
+// result, the result is printed in the format given by --output.
+type ResultFunc func(ctx context.Context, option CliOptions) (interface{}, error)
+
+// TextResult is a result printing itself in the text format, results without
+// Text are printed as a table in the text format. The results which are an
+// i18n.Localizer are printed with Localize in the language of the run instead.
+type TextResult interface {
+	Text() string
+}
+
+// TableResult is a result printing itself as a table. Results without Table
+// are printed as a table of their JSON fields.
+type TableResult interface {
+	Table() (header []string, rows [][]string)
+}
+
+// LocalizedTableResult is a result printing itself as a table with a header in
+// the language of the run, it is preferred to TableResult.
+type LocalizedTableResult interface {
+	LocalizeTable(p *i18n.Printer) (header []string, rows [][]string)
+}
+
+// WithCommandResultFunc functional options pattern to set a RunCommandFunc
+// returning a result.
+func WithCommandResultFunc(run ResultFunc) CommandOption {
+	return func(c *Command) {
+		c.runFunc = run.withResult()
+	}
+}
+
+// withResult adapts a ResultFunc to a RunContextFunc printing the result. The
+// result is printed even if the run failed, e.g. to report the files converted
+// before the failure. A dry run prints the recorded actions instead.
+func (run ResultFunc) withResult() RunContextFunc {
+	return func(ctx context.Context, option CliOptions) error {
+		result, err := run(ctx, option)
+		if result != nil && !IsDryRun(ctx) {
+			if printErr := PrintResult(ctx, result); printErr != nil && err == nil {
+				err = printErr
+			}
+		}
+		return err
+	}
+}
+
+// addOutputFlag adds the --output flag to the specified FlagSet object.
+func (a *App) addOutputFlag(fs *pflag.FlagSet) {
+	fs.StringVarP(&a.output, outputFlagName, "o", OutputText, "")
+	a.setFlagUsage(fs, outputFlagName, "app.flag.output", strings.Join(outputFormats, ", "))
+	cli.MarkValuesCompletion(fs, outputFlagName, outputFormats...)
+}
+
+// checkOutput validates --output, logs are moved to stderr for the run when the
+// results are printed in a machine-readable format so that they can be parsed.
+func (a *App) checkOutput(cmd *cobra.Command, args []string) error {
+	switch a.output {
+	case OutputText, OutputTable:
+	case OutputJSON, OutputYAML:
+		a.restoreLog = log.RedirectOutput("stderr")
+	default:
+		return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.output.hint", outputFlagName, strings.Join(outputFormats, ", ")),
+			i18n.Errorf("app.flag.invalid_format", outputFlagName, a.output))
+	}
+	return nil
+}
+
+// OutputFormat returns the format given by --output to the command in ctx.
+func OutputFormat(ctx context.Context) string {
+	if cmd := CommandFromContext(ctx); cmd != nil {
+		return outputFormat(cmd)
+	}
+	return OutputText
+}
+
+func outputFormat(cmd *cobra.Command) string {
+	if f := cmd.Flag(outputFlagName); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	return OutputText
+}
+
+// PrintResult prints result to the output of the command in ctx in the format
+// given by --output.
+func PrintResult(ctx context.Context, result interface{}) error {
+	cmd := CommandFromContext(ctx)
+	if cmd == nil {
+		return fmt.Errorf("no command in context")
+	}
+	return printResult(cmd.OutOrStdout(), i18n.FromContext(ctx), outputFormat(cmd), result)
+}
+
+// printResult prints result to w in format, the texts are in the language of p.
+func printResult(w io.Writer, p *i18n.Printer, format string, result interface{}) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case OutputYAML:
+		node, err := resultNode(result)
+		if err != nil {
+			return err
+		}
+		data, err := encodeYAML(node)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case OutputText:
+		if localizer, ok := result.(i18n.Localizer); ok {
+			_, err := io.WriteString(w, localizer.Localize(p))
+			return err
+		}
+		if text, ok := result.(TextResult); ok {
+			_, err := io.WriteString(w, text.Text())
+			return err
+		}
+	}
+
+	header, rows, err := resultTable(p, result)
+	if err != nil {
+		return err
+	}
+	return writeTable(w, header, rows)
+}
+
+// writeTable writes the header, if any, and the rows to w as columns separated
+// by two spaces. The cells are padded to their width in a terminal, see
+// cli.DisplayWidth, so that translated headers stay aligned.
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	if len(header) > 0 {
+		rows = append([][]string{header}, rows...)
+	}
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], cli.DisplayWidth(cell))
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			b.WriteString(cell)
+			// the last cell is not padded, like the last cell of a tabwriter line.
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-cli.DisplayWidth(cell)+2))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// resultNode returns the YAML node of the JSON encoding of result, the fields
+// keep their JSON names and order.
+func resultNode(result interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	return &doc, nil
+}
+
+// blockStyle resets the flow style of the nodes decoded from JSON.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// resultTable returns the header and the rows of result. Lists of objects have
+// a column per field, lists of scalars a row per value without header and
+// objects a row per field.
+func resultTable(p *i18n.Printer, result interface{}) ([]string, [][]string, error) {
+	if table, ok := result.(LocalizedTableResult); ok {
+		header, rows := table.LocalizeTable(p)
+		return header, rows, nil
+	}
+	if table, ok := result.(TableResult); ok {
+		header, rows := table.Table()
+		return header, rows, nil
+	}
+
+	node, err := resultNode(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := node.Content[0]
+	switch value.Kind {
+	case yaml.SequenceNode:
+		var header []string
+		index := map[string]int{}
+		for _, item := range value.Content {
+			for i := 0; item.Kind == yaml.MappingNode && i+1 < len(item.Content); i += 2 {
+				if _, ok := index[item.Content[i].Value]; !ok {
+					index[item.Content[i].Value] = len(header)
+					header = append(header, item.Content[i].Value)
+				}
+			}
+		}
+		var rows [][]string
+		for _, item := range value.Content {
+			row := make([]string, max(len(header), 1))
+			if item.Kind != yaml.MappingNode {
+				row[0] = cellValue(item)
+			}
+			for i := 0; item.Kind == yaml.MappingNode && i+1 < len(item.Content); i += 2 {
+				row[index[item.Content[i].Value]] = cellValue(item.Content[i+1])
+			}
+			rows = append(rows, row)
+		}
+		return upper(header), rows, nil
+	case yaml.MappingNode:
+		var rows [][]string
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			rows = append(rows, []string{value.Content[i].Value, cellValue(value.Content[i+1])})
+		}
+		return []string{"KEY", "VALUE"}, rows, nil
+	}
+	return nil, [][]string{{cellValue(value)}}, nil
+}
+
+// cellValue returns scalars as is and the other values as compact JSON.
+func cellValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return ""
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func upper(values []string) []string {
+	for i, value := range values {
+		values[i] = strings.ToUpper(value)
+	}
+	return values
+}
@@ -0,0 +1,85 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+type fileResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Size   int    `json:"size,omitempty"`
+}
+
+type textResult struct {
+	Files []fileResult `json:"files"`
+}
+
+func (r textResult) Text() string { return "converted " + r.Files[0].Name + "\n" }
+
+func Test_OutputFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		args   []string
+		want   string
+	}{
+		{
+			name:   "text result",
+			result: textResult{Files: []fileResult{{Name: "a.pdf", Status: "true"}}},
+			args:   []string{"convert"},
+			want:   "converted a.pdf\n",
+		},
+		{
+			name:   "json",
+			result: textResult{Files: []fileResult{{Name: "a.pdf", Status: "ok"}}},
+			args:   []string{"convert", "--output", "json"},
+			want:   "{\n  \"files\": [\n    {\n      \"name\": \"a.pdf\",\n      \"status\": \"ok\"\n    }\n  ]\n}\n",
+		},
+		{
+			name:   "yaml keeps json names and quotes strings",
+			result: textResult{Files: []fileResult{{Name: "a.pdf", Status: "true"}}},
+			args:   []string{"convert", "-o", "yaml"},
+			want:   "files:\n  - name: a.pdf\n    status: \"true\"\n",
+		},
+		{
+			name:   "table of a list",
+			result: []fileResult{{Name: "a.pdf", Status: "ok", Size: 10}, {Name: "b.pdf", Status: "failed"}},
+			args:   []string{"convert", "-o", "table"},
+			want:   "NAME   STATUS  SIZE\na.pdf  ok      10\nb.pdf  failed  \n",
+		},
+		{
+			name:   "text falls back to the table of an object",
+			result: fileResult{Name: "a.pdf", Status: "ok"},
+			args:   []string{"convert"},
+			want:   "KEY     VALUE\nname    a.pdf\nstatus  ok\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(
+				app.NewCommand("convert", "convert", app.WithCommandResultFunc(func(ctx context.Context, option app.CliOptions) (interface{}, error) {
+					return tt.result, nil
+				}))))
+			var stdout bytes.Buffer
+			a.Command().SetOut(&stdout)
+
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), tt.args))
+			assert.Equal(t, tt.want, stdout.String())
+		})
+	}
+}
+
+func Test_OutputInvalidFormat(t *testing.T) {
+	a := app.NewApp("test", "test", app.WithNoConfig(), app.WithCommands(app.NewCommand("convert", "convert")))
+	var stderr bytes.Buffer
+	a.Command().SetErr(&stderr)
+
+	assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(context.Background(), []string{"version", "-o", "xml"}))
+	assert.Contains(t, stderr.String(), `invalid --output format "xml"`)
+}
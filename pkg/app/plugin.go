@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginStopGracePeriod is how long a plugin is given to exit after it has
+// been interrupted before it is killed.
+const pluginStopGracePeriod = 5 * time.Second
+
+// plugin is an external executable named <command>-<name> dispatched as
+// `<command> <name>`.
+type plugin struct {
+	name string
+	path string
+	// shadowedBy is the path of the plugin with the same name found first.
+	shadowedBy string
+}
+
+// WithPlugins enables the discovery of external plugins: executables named
+// <command>-<name> on PATH or in ~/.<command>/plugins are run as `<command> <name>`.
+func WithPlugins() Option {
+	return func(a *App) {
+		a.plugins = true
+	}
+}
+
+// pluginDirs returns the directories searched for plugins in order.
+func (a *App) pluginDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "."+a.commandName, "plugins"))
+	}
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// discoverPlugins returns every plugin found in the plugin directories. A
+// plugin found after another one with the same name is returned with shadowedBy set.
+func (a *App) discoverPlugins() []plugin {
+	prefix := a.commandName + "-"
+	found := map[string]string{}
+	seen := map[string]bool{}
+
+	var plugins []plugin
+	for _, dir := range a.pluginDirs() {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+			}
+			if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			p := plugin{name: strings.TrimPrefix(name, prefix), path: path}
+			if first, ok := found[p.name]; ok {
+				p.shadowedBy = first
+			} else {
+				found[p.name] = path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// isBuiltin reports whether name is a command or an alias of a command of the app.
+func (a *App) isBuiltin(name string) bool {
+	switch name {
+	case "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range a.cmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlugin returns the plugin args dispatch to, if any. Built-in commands
+// always take precedence over plugins.
+func (a *App) findPlugin(args []string) (plugin, bool) {
+	if !a.plugins || len(args) == 0 || strings.HasPrefix(args[0], "-") || a.isBuiltin(args[0]) {
+		return plugin{}, false
+	}
+	for _, p := range a.discoverPlugins() {
+		if p.name == args[0] && p.shadowedBy == "" {
+			return p, true
+		}
+	}
+	return plugin{}, false
+}
+
+// runPlugin runs p with args and the environment of the process, the exit code
+// of the plugin is returned as is.
+func (a *App) runPlugin(ctx context.Context, p plugin, args []string) int {
+	cmd := exec.CommandContext(ctx, p.path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = a.cmd.OutOrStdout()
+	cmd.Stderr = a.cmd.ErrOrStderr()
+	cmd.Env = os.Environ()
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = pluginStopGracePeriod
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return ExitCodeOK
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return exitErr.ExitCode()
+	case ctx.Err() != nil:
+		return ExitCodeInterrupted
+	}
+	fmt.Fprintf(a.cmd.ErrOrStderr(), "%s %s\n", a.printer.T("app.error"), a.printer.T("app.plugin.run_failed", p.path, err))
+	return ExitCodeError
+}
+
+func (a *App) addPluginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "plugin",
+	}
+	a.setText(&cmd.Short, "app.plugin.short")
+	list := &cobra.Command{
+		Use:  "list",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var rows [][]string
+			for _, p := range a.discoverPlugins() {
+				warning := ""
+				switch {
+				case a.isBuiltin(p.name):
+					warning = a.printer.T("app.plugin.shadowed_builtin", p.name)
+				case p.shadowedBy != "":
+					warning = a.printer.T("app.plugin.shadowed", p.shadowedBy)
+				}
+				rows = append(rows, []string{p.name, p.path, warning})
+			}
+			return writeTable(cmd.OutOrStdout(), strings.Split(a.printer.T("app.plugin.list.header"), "\t"), rows)
+		},
+	}
+	a.setText(&list.Short, "app.plugin.list.short", a.commandName, a.commandName)
+	cmd.AddCommand(list)
+	return cmd
+}
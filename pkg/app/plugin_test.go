@@ -0,0 +1,60 @@
+//go:build !windows
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	assert.Nil(t, os.MkdirAll(dir, 0o755))
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	return path
+}
+
+func Test_Plugins(t *testing.T) {
+	home, bin := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PLUGIN_ENV", "from env")
+
+	hello := writePlugin(t, filepath.Join(home, ".tool", "plugins"), "tool-hello", `echo "$@ $PLUGIN_ENV"; exit 3`)
+	shadowed := writePlugin(t, bin, "tool-hello", "exit 0")
+	list := writePlugin(t, bin, "tool-list", "exit 0")
+	writePlugin(t, bin, "other-plugin", "exit 0")
+
+	newApp := func() (*app.App, *bytes.Buffer) {
+		a := app.NewApp("tool", "tool", app.WithNoConfig(), app.WithPlugins(), app.WithCommands(
+			app.NewCommand("download", "download", app.WithCommandRunFunc(func(option app.CliOptions) error { return nil })),
+		))
+		var stdout bytes.Buffer
+		a.Command().SetOut(&stdout)
+		return a, &stdout
+	}
+
+	a, stdout := newApp()
+	assert.Equal(t, 3, a.RunWithArgs(context.Background(), []string{"hello", "--flag", "arg"}))
+	assert.Equal(t, "--flag arg from env\n", stdout.String())
+
+	a, stdout = newApp()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"list"}))
+	assert.Contains(t, stdout.String(), "── download: download\n")
+	assert.Contains(t, stdout.String(), "└── hello: plugin "+hello+"\n")
+	assert.NotContains(t, stdout.String(), shadowed)
+	assert.NotContains(t, stdout.String(), list)
+
+	a, stdout = newApp()
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"plugin", "list"}))
+	assert.Regexp(t, `hello +`+hello+` *\n`, stdout.String())
+	assert.Regexp(t, `hello +`+shadowed+` +shadowed by `+hello+`\n`, stdout.String())
+	assert.Regexp(t, `list +`+list+` +shadowed by the built-in command list\n`, stdout.String())
+	assert.NotContains(t, stdout.String(), "other-plugin")
+}
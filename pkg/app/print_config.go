@@ -0,0 +1,163 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	printConfigFlagName = "print-config"
+
+	printConfigTable = "table"
+	printConfigJSON  = "json"
+)
+
+// optionSource is the effective value of an option and where it comes from.
+type optionSource struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+	// Source is one of default, flag, env:NAME or the file:line of the
+	// configuration file.
+	Source string `json:"source"`
+}
+
+// addPrintConfigFlag adds the --print-config flag to the specified FlagSet object.
+func (a *App) addPrintConfigFlag(fs *pflag.FlagSet) {
+	fs.StringVar(&a.printConfig, printConfigFlagName, a.printConfig, "")
+	a.setFlagUsage(fs, printConfigFlagName, "app.flag.print_config")
+	fs.Lookup(printConfigFlagName).NoOptDefVal = printConfigTable
+}
+
+// checkPrintConfig rejects --print-config on the commands which do not resolve
+// options, e.g. the config and the docs commands.
+func (a *App) checkPrintConfig(cmd *cobra.Command, args []string) error {
+	if _, ok := cmd.Annotations[annotationOptions]; a.printConfig != "" && !ok {
+		return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.hint.help", cmd.CommandPath()),
+			i18n.Errorf("app.print_config.unsupported", cmd.CommandPath(), printConfigFlagName))
+	}
+	return nil
+}
+
+// printOptionSources prints every option of the command at path resolved by v
+// in the format given by --print-config, an empty table or list when the
+// command has no options.
+func (a *App) printOptionSources(cmd *cobra.Command, path []string, v *viper.Viper, options CliOptions) error {
+	if a.printConfig != printConfigTable && a.printConfig != printConfigJSON {
+		return NewExitError(ExitCodeInvalidOptions, a.printer.T("app.print_config.hint"),
+			i18n.Errorf("app.flag.invalid_format", printConfigFlagName, a.printConfig))
+	}
+
+	sources := []optionSource{}
+	if options != nil {
+		fss := a.pathFlags(path, options)
+		for _, name := range fss.Order {
+			fss.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+				sources = append(sources, optionSource{
+					Name:   f.Name,
+					Value:  v.Get(f.Name),
+					Source: a.optionSource(cmd, path, f.Name),
+				})
+			})
+		}
+	}
+
+	if a.printConfig == printConfigJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(sources)
+	}
+	return printOptionTable(cmd.OutOrStdout(), a.printer, sources)
+}
+
+// optionSource returns where the value of the option key of the command at
+// path comes from, following the resolution order of the options.
+func (a *App) optionSource(cmd *cobra.Command, path []string, key string) string {
+	f := cmd.Flags().Lookup(key)
+	if f != nil && f.Changed {
+		return "flag"
+	}
+	var envs []string
+	if a.config != nil {
+		envs = append(envs, envName(append([]string{a.config.commandName}, path...), key))
+	}
+	if f != nil {
+		if env, ok := cli.FlagEnv(f); ok {
+			envs = append(envs, env)
+		}
+	}
+	for _, env := range envs {
+		// viper ignores the empty variables, so does the source.
+		if os.Getenv(env) != "" {
+			return "env:" + env
+		}
+	}
+	if keyPath, ok := a.configKey(path, key); ok {
+		return a.config.location(keyPath)
+	}
+
+	return "default"
+}
+
+// configKey returns the path of the option key of the command at path in the
+// configuration file, in the section of the command or in the global one.
+func (a *App) configKey(path []string, key string) ([]string, bool) {
+	if a.config == nil {
+		return nil, false
+	}
+	for _, section := range [][]string{path, {globalConfigSection}} {
+		keyPath := append(append([]string{}, section...), key)
+		if a.config.viper.InConfig(strings.Join(keyPath, ".")) {
+			return keyPath, true
+		}
+	}
+	return nil, false
+}
+
+// optionSet reports whether the option key of the command at path is set: by
+// its flag, by a non-empty environment variable or by a value of the
+// configuration file which is not a zero value, a key of the file holding ""
+// or false does not set the option.
+func (a *App) optionSet(cmd *cobra.Command, path []string, key string) bool {
+	source := a.optionSource(cmd, path, key)
+	if source == "default" {
+		return false
+	}
+	keyPath, ok := a.configKey(path, key)
+	if !ok || source == "flag" || strings.HasPrefix(source, "env:") {
+		return true
+	}
+	return !isZeroValue(a.config.viper.Get(strings.Join(keyPath, ".")))
+}
+
+// isZeroValue reports whether v, a value read from the configuration file, is
+// empty: nil, the zero value of a scalar or an empty list or mapping.
+func isZeroValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// printOptionTable prints sources as a table with a header in the language of p.
+func printOptionTable(w io.Writer, p *i18n.Printer, sources []optionSource) error {
+	rows := make([][]string, 0, len(sources))
+	for _, s := range sources {
+		rows = append(rows, []string{s.Name, fmt.Sprint(s.Value), s.Source})
+	}
+	return writeTable(w, strings.Split(p.T("app.print_config.header"), "\t"), rows)
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"github.com/lwm-galactic/utool/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// addVersionFlag adds the --version flag printing the version information to
+// the root command.
+func addVersionFlag(cmd *cobra.Command) {
+	info := version.Get()
+	cmd.Version = info.String()
+	cmd.SetVersionTemplate(info.Text())
+}
+
+// addVersionCmd creates the version command, the information is printed in
+// the format given by --output.
+func (a *App) addVersionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "version",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printResult(cmd.OutOrStdout(), a.printer, outputFormat(cmd), version.Get())
+		},
+	}
+	a.setText(&cmd.Short, "app.version.short")
+	return cmd
+}
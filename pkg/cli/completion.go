@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/lwm-galactic/utool/pkg/cli/flagtag"
+	"github.com/spf13/pflag"
+)
+
+// MarkFileCompletion restricts the shell completion of the flag name to files
+// with the given extensions, e.g. "pdf". Any file is completed when no
+// extension is given. It does nothing if the flag does not exist.
+func MarkFileCompletion(fs *pflag.FlagSet, name string, extensions ...string) {
+	flagtag.MarkFileCompletion(fs, name, extensions...)
+}
+
+// MarkDirCompletion restricts the shell completion of the flag name to
+// directories. It does nothing if the flag does not exist.
+func MarkDirCompletion(fs *pflag.FlagSet, name string) {
+	flagtag.MarkDirCompletion(fs, name)
+}
+
+// MarkValuesCompletion completes the flag name with the given values. It does
+// nothing if the flag does not exist.
+func MarkValuesCompletion(fs *pflag.FlagSet, name string, values ...string) {
+	flagtag.MarkValuesCompletion(fs, name, values...)
+}
+
+// CompletionValues returns the values set by MarkValuesCompletion on f.
+func CompletionValues(f *pflag.Flag) ([]string, bool) {
+	return flagtag.CompletionValues(f)
+}
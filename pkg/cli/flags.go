@@ -2,6 +2,8 @@ package cli
 
 import (
 	"flag"
+	"github.com/lwm-galactic/utool/pkg/cli/flagtag"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/lwm-galactic/utool/pkg/log"
 
 	"github.com/spf13/pflag"
@@ -39,3 +41,22 @@ func PrintFlags(flags *pflag.FlagSet) {
 		log.Debugf("FLAG: --%s=%q", flag.Name, flag.Value)
 	})
 }
+
+// ResetFlags sets the flags of fs back to their default value and marks them
+// as not changed, so that the flags of a command tree can be parsed again, see
+// flagtag.Reset.
+func ResetFlags(fs *pflag.FlagSet) {
+	flagtag.Reset(fs)
+}
+
+// LocalizeFlags makes the flags of fs report the errors of their values in the
+// language of p, see flagtag.Localize.
+func LocalizeFlags(fs *pflag.FlagSet, p *i18n.Printer) {
+	flagtag.Localize(fs, p)
+}
+
+// FlagMessageKeys returns the message keys of the usage and of the deprecation
+// message of a flag built from struct tags, see flagtag.MessageKeys.
+func FlagMessageKeys(f *pflag.Flag) (usage, deprecated string, ok bool) {
+	return flagtag.MessageKeys(f)
+}
@@ -0,0 +1,34 @@
+package flagtag
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagValuesAnnotation holds the fixed values a flag is completed with.
+const flagValuesAnnotation = "utool_annotation_completion_values"
+
+// MarkFileCompletion restricts the shell completion of the flag name to files
+// with the given extensions, e.g. "pdf". Any file is completed when no
+// extension is given. It does nothing if the flag does not exist.
+func MarkFileCompletion(fs *pflag.FlagSet, name string, extensions ...string) {
+	_ = fs.SetAnnotation(name, cobra.BashCompFilenameExt, extensions)
+}
+
+// MarkDirCompletion restricts the shell completion of the flag name to
+// directories. It does nothing if the flag does not exist.
+func MarkDirCompletion(fs *pflag.FlagSet, name string) {
+	_ = fs.SetAnnotation(name, cobra.BashCompSubdirsInDir, []string{})
+}
+
+// MarkValuesCompletion completes the flag name with the given values. It does
+// nothing if the flag does not exist.
+func MarkValuesCompletion(fs *pflag.FlagSet, name string, values ...string) {
+	_ = fs.SetAnnotation(name, flagValuesAnnotation, values)
+}
+
+// CompletionValues returns the values set by MarkValuesCompletion on f.
+func CompletionValues(f *pflag.Flag) ([]string, bool) {
+	values, ok := f.Annotations[flagValuesAnnotation]
+	return values, ok
+}
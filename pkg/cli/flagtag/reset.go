@@ -0,0 +1,90 @@
+package flagtag
+
+import (
+	"encoding/csv"
+	goflag "flag"
+	"strings"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/pflag"
+)
+
+// Reset sets the flags of fs back to their default value and marks them as not
+// changed, so that the flags of a command tree can be parsed again. The flags
+// of AddFlags get back the value their field had when they were added, the
+// other flags are set to their DefValue. The flags of the Go flag.CommandLine
+// added to fs keep their value, it is shared by the whole process.
+func Reset(fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		f.Changed = false
+		if goflag.CommandLine.Lookup(f.Name) != nil {
+			return
+		}
+		switch value := f.Value.(type) {
+		case *flagValue:
+			if value.reset != nil {
+				value.reset()
+			} else {
+				_ = value.Value.Set(f.DefValue)
+			}
+			value.set = false
+		case pflag.SliceValue:
+			def := sliceDefault(f.DefValue)
+			_ = value.Replace(def)
+			f.Value = &flagValue{
+				Value: f.Value,
+				reset: func() { _ = value.Replace(def) },
+				clear: func() { _ = value.Replace(nil) },
+			}
+		default:
+			_ = f.Value.Set(f.DefValue)
+		}
+	})
+}
+
+// Localize makes the flags of fs added by AddFlags and Var report the errors
+// of their values in the language of p. pflag prints the errors of the values
+// as soon as they are returned, they can not be translated afterwards.
+func Localize(fs *pflag.FlagSet, p *i18n.Printer) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if value, ok := f.Value.(*flagValue); ok {
+			value.printer = p
+		}
+	})
+}
+
+// flagValue is the value of the flags of AddFlags and Var. Reset sets it back
+// with reset, pflag slice and map values add to the values of the previous
+// parse once they are set, so the first Set after a reset starts from clear.
+type flagValue struct {
+	pflag.Value
+	reset func()
+	clear func()
+	// set: -true Set was called since the last reset.
+	set bool
+	// printer: language of the errors of Set, see Localize.
+	printer *i18n.Printer
+}
+
+func (v *flagValue) Set(value string) error {
+	if !v.set {
+		v.set = true
+		if v.clear != nil {
+			v.clear()
+		}
+	}
+	return v.printer.Localize(v.Value.Set(value))
+}
+
+// sliceDefault returns the values of the DefValue of a slice flag, e.g. [a,b].
+func sliceDefault(def string) []string {
+	def = strings.TrimSuffix(strings.TrimPrefix(def, "["), "]")
+	if def == "" {
+		return nil
+	}
+	values, err := csv.NewReader(strings.NewReader(def)).Read()
+	if err != nil {
+		return strings.Split(def, ",")
+	}
+	return values
+}
@@ -0,0 +1,281 @@
+// Package flagtag builds pflag flags and their checks from the tags of the
+// fields of an options struct, and provides typed flag values checking their
+// input when the flags are parsed. It only depends on i18n, so that every
+// package, e.g. log, can declare its options with tags.
+package flagtag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/pflag"
+)
+
+// Tags read by AddFlags and Validate on the fields of an options struct.
+const (
+	// TagFlag is the flag name, the mapstructure name of the field by default.
+	// "-" leaves the field out.
+	TagFlag = "flag"
+	// TagShort is the one letter shorthand of the flag.
+	TagShort = "short"
+	// TagUsage is the usage of the flag, a message key translated by i18n.T or
+	// plain text.
+	TagUsage = "usage"
+	// TagSection is the name of the flag set of the flag, the section given to
+	// AddFlags by default.
+	TagSection = "section"
+	// TagEnv is an environment variable read for the flag in addition to the
+	// ones derived from the command names, see FlagEnv.
+	TagEnv = "env"
+	// TagDefault is the default value of the flag, it is only set when the
+	// field holds its zero value, see isZero.
+	TagDefault = "default"
+	// TagRequired set to "true" makes Validate fail when the field holds its
+	// zero value.
+	TagRequired = "required"
+	// TagEnum lists the comma separated values allowed for the flag, they are
+	// also used to complete it.
+	TagEnum = "enum"
+	// TagDeprecated marks the flag as deprecated, it is the message printed
+	// when the flag is used, a message key or plain text.
+	TagDeprecated = "deprecated"
+)
+
+const (
+	// flagEnvAnnotation holds the environment variable set by the env tag.
+	flagEnvAnnotation = "utool_annotation_env"
+	// flagMessagesAnnotation holds the keys of the usage and of the deprecation
+	// message of the flag, see MessageKeys.
+	flagMessagesAnnotation = "utool_annotation_messages"
+)
+
+// AddFlags adds the flags of the fields of the struct pointed to by options
+// to the flag sets returned by flagSet for their section, e.g.
+//
+//	type Options struct {
+//		OutputDir string `mapstructure:"output-dir" short:"o" usage:"cmd.download.flag.output_dir" default:"."`
+//		Format    string `mapstructure:"format" usage:"Output format." enum:"json,yaml"`
+//	}
+//
+// Fields without flag or mapstructure name are left out, nested structs add
+// their flags with their name as prefix, e.g. log.level, unless squashed. The
+// flag names start with prefix, and the flags are added to section unless
+// they have a section tag. AddFlags panics when options is not a pointer to a
+// struct or when a field has a type it can not bind.
+func AddFlags(options interface{}, prefix, section string, flagSet func(section string) *pflag.FlagSet) {
+	v := reflect.ValueOf(options)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cli: options must be a pointer to a struct, got %T", options))
+	}
+	visitFields(v.Elem(), prefix, section, func(field structField) {
+		fs := flagSet(field.section)
+		addFieldFlag(fs, field)
+		f := fs.Lookup(field.name)
+		if value := field.tag.Get(TagDefault); value != "" && isZero(field.value) {
+			if err := f.Value.Set(value); err != nil {
+				panic(fmt.Sprintf("cli: invalid default of flag %s: %v", field.name, err))
+			}
+			f.DefValue = f.Value.String()
+		}
+		f.Value = fieldValue(f.Value, field.value)
+		deprecated := field.tag.Get(TagDeprecated)
+		if deprecated != "" {
+			_ = fs.MarkDeprecated(field.name, i18n.T(deprecated))
+		}
+		_ = fs.SetAnnotation(field.name, flagMessagesAnnotation, []string{field.tag.Get(TagUsage), deprecated})
+		if env := field.tag.Get(TagEnv); env != "" {
+			_ = fs.SetAnnotation(field.name, flagEnvAnnotation, []string{env})
+		}
+		if enum := field.enum(); len(enum) > 0 {
+			MarkValuesCompletion(fs, field.name, enum...)
+		}
+	})
+}
+
+// Validate checks the required and enum tags of the fields of the struct
+// pointed to by options.
+func Validate(options interface{}) []error {
+	v := reflect.ValueOf(options)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return []error{fmt.Errorf("options must be a pointer to a struct, got %T", options)}
+	}
+	var errs []error
+	visitFields(v.Elem(), "", "", func(field structField) {
+		if field.tag.Get(TagRequired) == "true" && isZero(field.value) {
+			errs = append(errs, i18n.Errorf("cli.validate.required", field.name))
+		}
+		if enum := field.enum(); len(enum) > 0 && !isZero(field.value) {
+			for _, value := range fieldValues(field.value) {
+				if !contains(enum, value) {
+					errs = append(errs, i18n.Errorf("cli.validate.enum", field.name, value, strings.Join(enum, ", ")))
+				}
+			}
+		}
+	})
+	return errs
+}
+
+// FlagEnv returns the environment variable set by the env tag of f.
+func FlagEnv(f *pflag.Flag) (string, bool) {
+	if env := f.Annotations[flagEnvAnnotation]; len(env) > 0 {
+		return env[0], true
+	}
+	return "", false
+}
+
+// MessageKeys returns the message keys of the usage and of the deprecation
+// message of f given by the usage and deprecated tags, so that they can be
+// translated again in another language. ok is false when f is not added by
+// AddFlags.
+func MessageKeys(f *pflag.Flag) (usage, deprecated string, ok bool) {
+	if keys := f.Annotations[flagMessagesAnnotation]; len(keys) == 2 {
+		return keys[0], keys[1], true
+	}
+	return "", "", false
+}
+
+// structField is a field of an options struct bound to a flag.
+type structField struct {
+	name    string
+	section string
+	tag     reflect.StructTag
+	value   reflect.Value
+}
+
+func (f structField) enum() []string {
+	if enum := f.tag.Get(TagEnum); enum != "" {
+		return strings.Split(enum, ",")
+	}
+	return nil
+}
+
+var flagValueType = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+
+// visitFields calls visit for every field of the struct v bound to a flag.
+func visitFields(v reflect.Value, prefix, section string, visit func(field structField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, squash := fieldName(sf)
+		if name == "-" || (name == "" && !squash) {
+			continue
+		}
+
+		fieldSection := section
+		if s := sf.Tag.Get(TagSection); s != "" {
+			fieldSection = s
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && !value.Addr().Type().Implements(flagValueType) {
+			nested := prefix
+			if !squash {
+				nested += name + "."
+			}
+			visitFields(value, nested, fieldSection, visit)
+			continue
+		}
+		visit(structField{name: prefix + name, section: fieldSection, tag: sf.Tag, value: value})
+	}
+}
+
+// fieldName returns the flag name of sf and whether it is a squashed struct.
+func fieldName(sf reflect.StructField) (string, bool) {
+	if name, ok := sf.Tag.Lookup(TagFlag); ok {
+		return name, false
+	}
+	name, opts, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+	return name, opts == "squash"
+}
+
+// addFieldFlag adds the flag of field to fs, the flag sets the field.
+func addFieldFlag(fs *pflag.FlagSet, field structField) {
+	name, short, usage := field.name, field.tag.Get(TagShort), i18n.T(field.tag.Get(TagUsage))
+	switch p := field.value.Addr().Interface().(type) {
+	case pflag.Value:
+		Var(fs, p, name, short, usage)
+	case *string:
+		fs.StringVarP(p, name, short, *p, usage)
+	case *bool:
+		fs.BoolVarP(p, name, short, *p, usage)
+	case *int:
+		fs.IntVarP(p, name, short, *p, usage)
+	case *int64:
+		fs.Int64VarP(p, name, short, *p, usage)
+	case *uint:
+		fs.UintVarP(p, name, short, *p, usage)
+	case *float64:
+		fs.Float64VarP(p, name, short, *p, usage)
+	case *time.Duration:
+		fs.DurationVarP(p, name, short, *p, usage)
+	case *[]string:
+		fs.StringSliceVarP(p, name, short, *p, usage)
+	case *[]int:
+		fs.IntSliceVarP(p, name, short, *p, usage)
+	case *map[string]string:
+		fs.StringToStringVarP(p, name, short, *p, usage)
+	default:
+		panic(fmt.Sprintf("cli: unsupported type %s of flag %s", field.value.Type(), name))
+	}
+}
+
+// fieldValue wraps the flag value of field so that Reset sets the field
+// back to the value it holds now.
+func fieldValue(value pflag.Value, field reflect.Value) pflag.Value {
+	def := reflect.New(field.Type()).Elem()
+	def.Set(field)
+	v, ok := value.(*flagValue)
+	if !ok {
+		v = &flagValue{Value: value}
+	}
+	v.set = false
+	v.reset = func() { field.Set(def) }
+	v.clear = func() {
+		switch field.Kind() {
+		case reflect.Slice:
+			field.Set(reflect.Zero(field.Type()))
+		case reflect.Map:
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+	}
+	return v
+}
+
+// isZero reports whether v holds its zero value, the values implementing
+// pflag.Value are also zero when they print as an empty string, e.g. a cli.File
+// with extensions but without path.
+func isZero(v reflect.Value) bool {
+	if value, ok := v.Addr().Interface().(pflag.Value); ok && value.String() == "" {
+		return true
+	}
+	return v.IsZero()
+}
+
+// fieldValues returns the values of a slice field, the value of other fields.
+func fieldValues(v reflect.Value) []string {
+	if v.Kind() == reflect.Slice {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return values
+	}
+	if value, ok := v.Addr().Interface().(pflag.Value); ok {
+		return []string{value.String()}
+	}
+	return []string{fmt.Sprint(v.Interface())}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
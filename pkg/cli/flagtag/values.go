@@ -0,0 +1,409 @@
+package flagtag
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/pflag"
+)
+
+// CompletionHinter is a flag value carrying the shell completion hints of its
+// flag, AddFlags and Var set them when the flag is added.
+type CompletionHinter interface {
+	// MarkCompletion sets the completion hints of the flag name of fs.
+	MarkCompletion(fs *pflag.FlagSet, name string)
+}
+
+// Var adds the flag name with the given value to fs and sets the completion
+// hints of the value. The errors of the value are reported in the language
+// given by Localize.
+func Var(fs *pflag.FlagSet, value pflag.Value, name, shorthand, usage string) {
+	fs.VarP(&flagValue{Value: value}, name, shorthand, usage)
+	if hinter, ok := value.(CompletionHinter); ok {
+		hinter.MarkCompletion(fs, name)
+	}
+}
+
+// DecodeValueHook is a mapstructure decode hook setting the option fields
+// which implement pflag.Value, e.g. the values of this package, with their Set
+// method. The values read from the configuration and the environment are then
+// checked like the flags, and the settings of the fields, e.g. the extensions
+// of a File, are kept.
+func DecodeValueHook(from, to reflect.Value) (interface{}, error) {
+	if !from.IsValid() {
+		return nil, nil
+	}
+	if from.Type() == to.Type() {
+		return from.Interface(), nil
+	}
+	p := reflect.New(to.Type())
+	value, ok := p.Interface().(pflag.Value)
+	if !ok {
+		return from.Interface(), nil
+	}
+
+	var s string
+	switch from.Kind() {
+	case reflect.String:
+		s = from.String()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		s = fmt.Sprint(from.Interface())
+	default:
+		return from.Interface(), nil
+	}
+	p.Elem().Set(to)
+	if err := value.Set(s); err != nil {
+		return nil, err
+	}
+	return p.Elem().Interface(), nil
+}
+
+// File is an existing file, with one of Extensions when they are set. The
+// files with the extensions are completed.
+type File struct {
+	Path string
+	// Extensions are the allowed extensions without dot, e.g. "pdf".
+	Extensions []string
+}
+
+// Set implements pflag.Value, an empty value unsets the file.
+func (f *File) Set(value string) error {
+	if value == "" {
+		f.Path = ""
+		return nil
+	}
+	info, err := os.Stat(value)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return i18n.Errorf("cli.value.file_not_exist", value)
+		}
+		return err
+	}
+	if info.IsDir() {
+		return i18n.Errorf("cli.value.file_is_dir", value)
+	}
+	if len(f.Extensions) > 0 && !hasExtension(value, f.Extensions) {
+		extensions := make([]string, 0, len(f.Extensions))
+		for _, e := range f.Extensions {
+			extensions = append(extensions, "."+e)
+		}
+		return i18n.Errorf("cli.value.file_extension", value, i18n.Join(extensions, i18n.Or))
+	}
+	f.Path = value
+	return nil
+}
+
+func (f File) String() string { return f.Path }
+
+// Type implements pflag.Value.
+func (f *File) Type() string { return "file" }
+
+// MarshalText prints the file as its path.
+func (f File) MarshalText() ([]byte, error) { return []byte(f.Path), nil }
+
+// MarkCompletion implements CompletionHinter.
+func (f *File) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkFileCompletion(fs, name, f.Extensions...)
+}
+
+func hasExtension(path string, extensions []string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, e := range extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir is an existing directory, or a directory which can be created when
+// Create is set. The directories are completed.
+type Dir struct {
+	Path string
+	// Create allows a directory which does not exist yet when its closest
+	// existing parent is a directory.
+	Create bool
+}
+
+// Set implements pflag.Value, an empty value unsets the directory.
+func (d *Dir) Set(value string) error {
+	if value == "" {
+		d.Path = ""
+		return nil
+	}
+	info, err := os.Stat(value)
+	switch {
+	case err == nil && !info.IsDir():
+		return i18n.Errorf("cli.value.not_dir", value)
+	case err != nil && d.Create:
+		if cerr := creatable(value); cerr != nil {
+			return cerr
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		return i18n.Errorf("cli.value.dir_not_exist", value)
+	case err != nil:
+		return err
+	}
+	d.Path = value
+	return nil
+}
+
+// creatable checks that the closest existing parent of dir is a directory.
+func creatable(dir string) error {
+	for parent := filepath.Dir(filepath.Clean(dir)); ; parent = filepath.Dir(parent) {
+		info, err := os.Stat(parent)
+		if err == nil {
+			if !info.IsDir() {
+				return i18n.Errorf("cli.value.dir_not_creatable", dir, parent)
+			}
+			return nil
+		}
+		if !errors.Is(err, os.ErrNotExist) || parent == filepath.Dir(parent) {
+			return err
+		}
+	}
+}
+
+func (d Dir) String() string { return d.Path }
+
+// Type implements pflag.Value.
+func (d *Dir) Type() string { return "dir" }
+
+// MarshalText prints the directory as its path.
+func (d Dir) MarshalText() ([]byte, error) { return []byte(d.Path), nil }
+
+// MarkCompletion implements CompletionHinter.
+func (d *Dir) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkDirCompletion(fs, name)
+}
+
+// Enum is one of Values, which are completed.
+type Enum struct {
+	Value  string
+	Values []string
+}
+
+// Set implements pflag.Value, an empty value unsets the enum.
+func (e *Enum) Set(value string) error {
+	if value != "" && !contains(e.Values, value) {
+		return i18n.Errorf("cli.value.enum", strings.Join(e.Values, ", "))
+	}
+	e.Value = value
+	return nil
+}
+
+func (e Enum) String() string { return e.Value }
+
+// Type implements pflag.Value.
+func (e *Enum) Type() string { return "enum" }
+
+// MarshalText prints the enum as its value.
+func (e Enum) MarshalText() ([]byte, error) { return []byte(e.Value), nil }
+
+// MarkCompletion implements CompletionHinter.
+func (e *Enum) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkValuesCompletion(fs, name, e.Values...)
+}
+
+// byte size units, the SI units are powers of 1000 and the IEC units powers of 1024.
+var byteUnits = []struct {
+	name string
+	size int64
+}{
+	{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+	{"B", 1},
+}
+
+// ByteSize is a number of bytes written with a unit, e.g. 10MB or 1.5GiB. The
+// SI units KB, MB, GB and TB are powers of 1000, the IEC units KiB, MiB, GiB
+// and TiB powers of 1024, a number without unit is a number of bytes.
+type ByteSize int64
+
+// Set implements pflag.Value.
+func (b *ByteSize) Set(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(size)
+	return nil
+}
+
+func parseByteSize(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if len(s) > len(u.name) && strings.EqualFold(s[len(s)-len(u.name):], u.name) {
+			s, unit = strings.TrimSpace(s[:len(s)-len(u.name)]), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) || n*float64(unit) >= math.MaxInt64 {
+		return 0, i18n.Errorf("cli.value.size", value)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// String returns the size with the largest unit dividing it, e.g. 10MB.
+func (b ByteSize) String() string {
+	return formatByteSize(int64(b))
+}
+
+func formatByteSize(size int64) string {
+	if size == 0 {
+		return "0B"
+	}
+	for _, u := range byteUnits {
+		if size%u.size == 0 {
+			return strconv.FormatInt(size/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+// Type implements pflag.Value.
+func (b *ByteSize) Type() string { return "size" }
+
+// MarshalText prints the size with its unit.
+func (b ByteSize) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// MarkCompletion implements CompletionHinter, sizes are not completed with files.
+func (b *ByteSize) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkValuesCompletion(fs, name)
+}
+
+// Rate is a number of bytes per second written as a ByteSize followed by /s,
+// e.g. 2MB/s.
+type Rate int64
+
+// Set implements pflag.Value.
+func (r *Rate) Set(value string) error {
+	size, ok := strings.CutSuffix(strings.TrimSpace(value), "/s")
+	if !ok {
+		return i18n.Errorf("cli.value.rate", value)
+	}
+	n, err := parseByteSize(size)
+	if err != nil {
+		return i18n.Errorf("cli.value.rate", value)
+	}
+	*r = Rate(n)
+	return nil
+}
+
+func (r Rate) String() string { return formatByteSize(int64(r)) + "/s" }
+
+// Type implements pflag.Value.
+func (r *Rate) Type() string { return "rate" }
+
+// MarshalText prints the rate with its unit.
+func (r Rate) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// MarkCompletion implements CompletionHinter, rates are not completed with files.
+func (r *Rate) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkValuesCompletion(fs, name)
+}
+
+// DurationRange is a duration between Min and Max, there is no upper bound
+// when Max is zero. The zero duration means unset and is always allowed.
+type DurationRange struct {
+	Value    time.Duration
+	Min, Max time.Duration
+}
+
+// Set implements pflag.Value.
+func (d *DurationRange) Set(value string) error {
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return i18n.Errorf("cli.value.duration", value)
+	}
+	if v != 0 && (v < d.Min || (d.Max > 0 && v > d.Max)) {
+		if d.Max > 0 {
+			return i18n.Errorf("cli.value.duration_range", d.Min, d.Max)
+		}
+		return i18n.Errorf("cli.value.duration_min", d.Min)
+	}
+	d.Value = v
+	return nil
+}
+
+func (d DurationRange) String() string { return d.Value.String() }
+
+// Type implements pflag.Value.
+func (d *DurationRange) Type() string { return "duration" }
+
+// MarshalText prints the duration.
+func (d DurationRange) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// MarkCompletion implements CompletionHinter, durations are not completed with files.
+func (d *DurationRange) MarkCompletion(fs *pflag.FlagSet, name string) {
+	MarkValuesCompletion(fs, name)
+}
+
+// URL is an absolute url with one of Schemes, http or https when they are not
+// set. The schemes are completed.
+type URL struct {
+	URL     *url.URL
+	Schemes []string
+}
+
+// Set implements pflag.Value, an empty value unsets the url.
+func (u *URL) Set(value string) error {
+	if value == "" {
+		u.URL = nil
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	schemes := u.schemes()
+	if !contains(schemes, parsed.Scheme) || parsed.Host == "" {
+		return i18n.Errorf("cli.value.url_scheme", i18n.Join(schemes, i18n.Or))
+	}
+	u.URL = parsed
+	return nil
+}
+
+func (u *URL) schemes() []string {
+	if len(u.Schemes) > 0 {
+		return u.Schemes
+	}
+	return []string{"http", "https"}
+}
+
+func (u URL) String() string {
+	if u.URL == nil {
+		return ""
+	}
+	return u.URL.String()
+}
+
+// Type implements pflag.Value.
+func (u *URL) Type() string { return "url" }
+
+// MarshalText prints the url.
+func (u URL) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// MarkCompletion implements CompletionHinter.
+func (u *URL) MarkCompletion(fs *pflag.FlagSet, name string) {
+	var prefixes []string
+	for _, scheme := range u.schemes() {
+		prefixes = append(prefixes, scheme+"://")
+	}
+	MarkValuesCompletion(fs, name, prefixes...)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"github.com/lwm-galactic/utool/pkg/i18n"
+)
+
+// FlagGroupKind is the rule a FlagGroup enforces on its flags.
+type FlagGroupKind int
+
+const (
+	// Required flags must all be set.
+	Required FlagGroupKind = iota
+	// MutuallyExclusive flags can not be set together.
+	MutuallyExclusive
+	// OneRequired needs at least one of the flags to be set.
+	OneRequired
+	// Requires needs the other flags to be set when the first one is set.
+	Requires
+)
+
+// FlagGroup is a rule on the flags of NamedFlagSets, the flags are named
+// without dashes.
+type FlagGroup struct {
+	Kind  FlagGroupKind
+	Flags []string
+}
+
+// MarkRequired requires every flag of names to be set.
+func (nfs *NamedFlagSets) MarkRequired(names ...string) {
+	for _, name := range names {
+		nfs.Groups = append(nfs.Groups, FlagGroup{Kind: Required, Flags: []string{name}})
+	}
+}
+
+// MarkMutuallyExclusive forbids to set more than one flag of names.
+func (nfs *NamedFlagSets) MarkMutuallyExclusive(names ...string) {
+	nfs.Groups = append(nfs.Groups, FlagGroup{Kind: MutuallyExclusive, Flags: names})
+}
+
+// MarkOneRequired requires at least one flag of names to be set, mark them
+// mutually exclusive too to require exactly one.
+func (nfs *NamedFlagSets) MarkOneRequired(names ...string) {
+	nfs.Groups = append(nfs.Groups, FlagGroup{Kind: OneRequired, Flags: names})
+}
+
+// MarkRequires requires the flags required to be set when the flag name is set.
+func (nfs *NamedFlagSets) MarkRequires(name string, required ...string) {
+	nfs.Groups = append(nfs.Groups, FlagGroup{Kind: Requires, Flags: append([]string{name}, required...)})
+}
+
+// CheckGroups returns an error per group of nfs which is not satisfied, isSet
+// reports whether a flag is set.
+func (nfs *NamedFlagSets) CheckGroups(isSet func(name string) bool) []error {
+	var errs []error
+	for _, group := range nfs.Groups {
+		var set []string
+		for _, name := range group.Flags {
+			if isSet(name) {
+				set = append(set, name)
+			}
+		}
+
+		switch group.Kind {
+		case Required:
+			if len(set) == 0 {
+				errs = append(errs, i18n.Errorf("cli.group.required", group.Flags[0]))
+			}
+		case MutuallyExclusive:
+			if len(set) > 1 {
+				errs = append(errs, i18n.Errorf("cli.group.exclusive", joinFlags(set, i18n.And)))
+			}
+		case OneRequired:
+			if len(set) == 0 {
+				errs = append(errs, i18n.Errorf("cli.group.one_required", joinFlags(group.Flags, i18n.Or)))
+			}
+		case Requires:
+			if isSet(group.Flags[0]) {
+				var missing []string
+				for _, name := range group.Flags[1:] {
+					if !isSet(name) {
+						missing = append(missing, name)
+					}
+				}
+				if len(missing) > 0 {
+					errs = append(errs, i18n.Errorf("cli.group.requires", group.Flags[0], joinFlags(missing, i18n.And)))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// String describes the group as shown in the help.
+func (g FlagGroup) String() string {
+	return g.Localize(nil)
+}
+
+// Localize describes the group in the language of p, it implements
+// i18n.Localizer.
+func (g FlagGroup) Localize(p *i18n.Printer) string {
+	switch g.Kind {
+	case Required:
+		return p.T("cli.group.required.help", g.Flags[0])
+	case MutuallyExclusive:
+		return p.T("cli.group.exclusive.help", joinFlags(g.Flags, i18n.And))
+	case OneRequired:
+		return p.T("cli.group.one_required.help", joinFlags(g.Flags, i18n.Or))
+	case Requires:
+		return p.T("cli.group.requires.help", g.Flags[0], joinFlags(g.Flags[1:], i18n.And))
+	}
+	return ""
+}
+
+// joinFlags joins names as --a, --b and --c.
+func joinFlags(names []string, conjunction string) i18n.List {
+	flags := make([]string, 0, len(names))
+	for _, name := range names {
+		flags = append(flags, "--"+name)
+	}
+	return i18n.Join(flags, conjunction)
+}
@@ -0,0 +1,73 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGroupFlagSets() cli.NamedFlagSets {
+	var fss cli.NamedFlagSets
+	fs := fss.FlagSet("download")
+	for _, name := range []string{"url", "file", "output-dir", "cookies", "token"} {
+		fs.String(name, "", name)
+	}
+	fss.MarkOneRequired("url", "file")
+	fss.MarkMutuallyExclusive("url", "file")
+	fss.MarkRequires("cookies", "url", "token")
+	fss.MarkRequired("output-dir")
+	return fss
+}
+
+func Test_CheckGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		set  []string
+		errs []string
+	}{
+		{name: "valid", set: []string{"url", "output-dir"}},
+		{
+			name: "none",
+			errs: []string{"one of --url or --file is required", "--output-dir is required"},
+		},
+		{
+			name: "exclusive",
+			set:  []string{"url", "file", "output-dir"},
+			errs: []string{"--url and --file can not be used together"},
+		},
+		{
+			name: "requires",
+			set:  []string{"file", "cookies", "output-dir"},
+			errs: []string{"--cookies requires --url and --token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fss := newGroupFlagSets()
+			set := map[string]bool{}
+			for _, name := range tt.set {
+				set[name] = true
+			}
+			var errs []string
+			for _, err := range fss.CheckGroups(func(name string) bool { return set[name] }) {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
+
+func Test_PrintSectionsGroups(t *testing.T) {
+	var buf bytes.Buffer
+	cli.PrintSections(&buf, newGroupFlagSets(), 0)
+
+	assert.Contains(t, buf.String(), `
+  One of --url or --file is required.
+  --url and --file can not be used together.
+  --cookies requires --url and --token.
+  --output-dir is required.
+`)
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/spf13/pflag"
+	"golang.org/x/text/width"
+)
+
+const (
+	// helpIndent is the indentation of the rows of a help section.
+	helpIndent = "  "
+	// helpGap separates the two columns of the rows.
+	helpGap = "   "
+	// minDescriptionWidth is the narrowest description column, narrower
+	// descriptions start on the line below their term.
+	minDescriptionWidth = 24
+	// belowIndent is the indentation of the descriptions printed below their term.
+	belowIndent = 10
+)
+
+var headingColor = color.New(color.FgYellow, color.Bold)
+
+// Heading returns the title of a help section, colored unless NO_COLOR is set
+// or the output is not a terminal.
+func Heading(title string) string {
+	if os.Getenv("NO_COLOR") != "" {
+		return title
+	}
+	return headingColor.Sprint(title)
+}
+
+// DisplayWidth returns the number of columns s takes in a terminal: East Asian
+// wide and fullwidth runes, e.g. 中文, take two columns, combining marks and
+// ANSI escape sequences none.
+func DisplayWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if end := escapeEnd(s, i); end > i {
+			i = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		n += runeWidth(r)
+		i += size
+	}
+	return n
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+func isWide(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	}
+	return false
+}
+
+// escapeEnd returns the end of the ANSI escape sequence starting at i in s, i
+// when there is none.
+func escapeEnd(s string, i int) int {
+	if !strings.HasPrefix(s[i:], "\x1b[") {
+		return i
+	}
+	for j := i + 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// Wrap splits s into lines of at most width columns, see DisplayWidth. Lines
+// break at spaces and around wide runes, which are not separated by spaces in
+// Chinese, but not before closing punctuation. Words wider than width are not
+// broken. The newlines of s are kept, s is not wrapped when width is zero.
+func Wrap(s string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		lines = append(lines, wrapParagraph(paragraph, width)...)
+	}
+	return lines
+}
+
+func wrapParagraph(s string, width int) []string {
+	if width <= 0 || DisplayWidth(s) <= width {
+		return []string{s}
+	}
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, word := range splitWords(s) {
+		n := DisplayWidth(word)
+		if lineWidth > 0 && lineWidth+n > width {
+			lines = append(lines, strings.TrimRight(line.String(), " "))
+			line.Reset()
+			lineWidth = 0
+			if word == " " {
+				continue
+			}
+		}
+		line.WriteString(word)
+		lineWidth += n
+	}
+	return append(lines, strings.TrimRight(line.String(), " "))
+}
+
+// noBreakBefore are the punctuation marks which can not start a line.
+const noBreakBefore = ",.;:!?)]}'\"，。、；：！？）】」』》”’"
+
+// splitWords splits s into the units wrapped by Wrap: the spaces, the words and
+// the wide runes, with the punctuation following them.
+func splitWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		if end := escapeEnd(s, i); end > i {
+			word.WriteString(s[i:end])
+			i = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == ' ':
+			flush()
+			words = append(words, " ")
+		case word.Len() == 0 && len(words) > 0 && words[len(words)-1] != " " && strings.ContainsRune(noBreakBefore, r):
+			words[len(words)-1] += string(r)
+		case isWide(r):
+			flush()
+			words = append(words, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return words
+}
+
+// PrintDefinitions prints rows of a term and its description in two aligned
+// columns, the descriptions are wrapped to fit in cols columns. If cols is
+// zero, lines are not wrapped.
+func PrintDefinitions(w io.Writer, rows [][2]string, cols int) {
+	termWidth := 0
+	for _, row := range rows {
+		termWidth = max(termWidth, DisplayWidth(row[0]))
+	}
+	indent := len(helpIndent) + termWidth + len(helpGap)
+	below := cols > 0 && cols-indent < minDescriptionWidth
+
+	for _, row := range rows {
+		if below {
+			fmt.Fprintf(w, "%s%s\n", helpIndent, row[0])
+			pad := strings.Repeat(" ", belowIndent)
+			for _, line := range Wrap(row[1], cols-belowIndent) {
+				fmt.Fprintf(w, "%s%s\n", pad, line)
+			}
+			continue
+		}
+		lines := Wrap(row[1], cols-indent)
+		padding := strings.Repeat(" ", termWidth-DisplayWidth(row[0]))
+		fmt.Fprintf(w, "%s%s%s%s%s\n", helpIndent, row[0], padding, helpGap, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", indent), line)
+		}
+	}
+}
+
+// flagRows returns the term and the description of the visible flags of fs.
+func flagRows(p *i18n.Printer, fs *pflag.FlagSet) [][2]string {
+	var rows [][2]string
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		term, usage := FlagUsage(p, f)
+		rows = append(rows, [2]string{term, usage})
+	})
+	return rows
+}
+
+// FlagUsage returns the term of f as shown in the help, e.g.
+// "-o, --output FORMAT", and its usage followed by its default value in the
+// language of p.
+func FlagUsage(p *i18n.Printer, f *pflag.Flag) (term, usage string) {
+	term = "    --" + f.Name
+	if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+		term = "-" + f.Shorthand + ", --" + f.Name
+	}
+	varname, usage := pflag.UnquoteUsage(f)
+	if varname != "" {
+		term += " " + varname
+	}
+	if f.NoOptDefVal != "" {
+		switch f.Value.Type() {
+		case "string":
+			term += fmt.Sprintf("[=%q]", f.NoOptDefVal)
+		case "bool":
+			if f.NoOptDefVal != "true" {
+				term += "[=" + f.NoOptDefVal + "]"
+			}
+		case "count":
+			if f.NoOptDefVal != "+1" {
+				term += "[=" + f.NoOptDefVal + "]"
+			}
+		default:
+			term += "[=" + f.NoOptDefVal + "]"
+		}
+	}
+	if !defaultIsZero(f) {
+		value := f.DefValue
+		if f.Value.Type() == "string" {
+			value = fmt.Sprintf("%q", f.DefValue)
+		}
+		usage += p.T("cli.help.default", value)
+	}
+	if f.Deprecated != "" {
+		usage += p.T("cli.help.deprecated", f.Deprecated)
+	}
+	return term, usage
+}
+
+// defaultIsZero reports whether the default of f is the zero value of its
+// type, which pflag does not print.
+func defaultIsZero(f *pflag.Flag) bool {
+	switch f.Value.Type() {
+	case "bool":
+		return f.DefValue == "false"
+	case "duration":
+		return f.DefValue == "0" || f.DefValue == "0s"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "count":
+		return f.DefValue == "0"
+	case "string":
+		return f.DefValue == ""
+	}
+	switch f.DefValue {
+	case "", "0", "false", "<nil>", "[]":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DisplayWidth(t *testing.T) {
+	assert.Equal(t, 4, cli.DisplayWidth("file"))
+	assert.Equal(t, 8, cli.DisplayWidth("下载地址"))
+	assert.Equal(t, 9, cli.DisplayWidth("pdf转docx"))
+	assert.Equal(t, 4, cli.DisplayWidth("ＡＢ"))
+	assert.Equal(t, 8, cli.DisplayWidth("\x1b[32mdownload\x1b[0m"))
+}
+
+func Test_Wrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{name: "no wrap", text: "a b c", width: 0, want: []string{"a b c"}},
+		{name: "words", text: "read the configuration file", width: 10, want: []string{"read the", "configuration", "file"}},
+		{name: "chinese", text: "指定一个文件用于批量下载", width: 10, want: []string{"指定一个文", "件用于批量", "下载"}},
+		{name: "punctuation", text: "指定一个文件，用于下载", width: 10, want: []string{"指定一个文", "件，用于下", "载"}},
+		{name: "mixed", text: "转换成docx文件", width: 10, want: []string{"转换成docx", "文件"}},
+		{name: "newlines", text: "第一行\n第二行", width: 10, want: []string{"第一行", "第二行"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cli.Wrap(tt.text, tt.width))
+		})
+	}
+}
+
+func newHelpFlagSets() cli.NamedFlagSets {
+	var fss cli.NamedFlagSets
+	fs := fss.FlagSet("pdf2docx")
+	fs.String("file", "", "指定一个pdf文件转换成docx")
+	fs.StringP("output-dir", "o", ".", "转换后的docx文件的输出文件夹")
+	fs.Bool("gui", false, "是否使用图形化界面操作")
+	return fss
+}
+
+func Test_PrintSections(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var buf bytes.Buffer
+	cli.PrintSections(&buf, newHelpFlagSets(), 52)
+	assert.Equal(t, `
+Pdf2docx flags:
+
+      --file string         指定一个pdf文件转换成
+                            docx
+      --gui                 是否使用图形化界面操作
+  -o, --output-dir string   转换后的docx文件的输出文
+                            件夹 (default ".")
+`, buf.String())
+
+	buf.Reset()
+	cli.PrintSections(&buf, newHelpFlagSets(), 30)
+	assert.Equal(t, `
+Pdf2docx flags:
+
+      --file string
+          指定一个pdf文件转换
+          成docx
+      --gui
+          是否使用图形化界面操
+          作
+  -o, --output-dir string
+          转换后的docx文件的输
+          出文件夹 (default
+          ".")
+`, buf.String())
+}
+
+func Test_Heading(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	assert.Equal(t, "\x1b[33;1mUsage:\x1b[0;22m", cli.Heading("Usage:"))
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, "Usage:", cli.Heading("Usage:"))
+}
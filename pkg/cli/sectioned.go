@@ -1,8 +1,8 @@
 package cli
 
 import (
-	"bytes"
 	"fmt"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/spf13/pflag"
 	"io"
 	"strings"
@@ -14,6 +14,9 @@ type NamedFlagSets struct {
 	Order []string
 	// FlagSets stores the flag sets by name.
 	FlagSets map[string]*pflag.FlagSet
+	// Groups are the rules on the flags, e.g. flags which can not be used
+	// together.
+	Groups []FlagGroup
 }
 
 // FlagSet returns the flag set with the given name and adds it to the
@@ -32,32 +35,77 @@ func (nfs *NamedFlagSets) FlagSet(name string) *pflag.FlagSet {
 // PrintSections prints the given names flag sets in sections, with the maximal given column number.
 // If cols is zero, lines are not wrapped.
 func PrintSections(w io.Writer, fss NamedFlagSets, cols int) {
+	PrintLocalizedSections(w, nil, fss, cols)
+}
 
+// PrintLocalizedSections prints the sections like PrintSections in the
+// language of p.
+func PrintLocalizedSections(w io.Writer, p *i18n.Printer, fss NamedFlagSets, cols int) {
 	for _, name := range fss.Order {
 		fs := fss.FlagSets[name]
-		if !fs.HasFlags() {
+		if fs == nil || !fs.HasFlags() {
+			continue
+		}
+		rows := flagRows(p, fs)
+		if len(rows) == 0 {
 			continue
 		}
 
-		wideFS := pflag.NewFlagSet("", pflag.ExitOnError)
-		wideFS.AddFlagSet(fs)
+		fmt.Fprintf(w, "\n%s\n\n", Heading(SectionTitle(p, name)))
+		PrintDefinitions(w, rows, cols)
+		printGroups(w, p, fss.SectionGroups(name), cols)
+	}
+}
+
+// SectionTitle returns the title of the section name in the language of p,
+// e.g. "Download flags:".
+func SectionTitle(p *i18n.Printer, name string) string {
+	return p.T("cli.help.flags", strings.ToUpper(name[:1])+name[1:])
+}
 
-		var zzz string
-		if cols > 24 {
-			zzz = strings.Repeat("z", cols-24)
-			wideFS.Int(zzz, 0, strings.Repeat("z", cols-24))
+// SectionGroups returns the groups shown in the section name, the ones whose
+// first flag is in the section. The groups of hidden flags, e.g. deprecated
+// ones, are not shown.
+func (nfs NamedFlagSets) SectionGroups(name string) []FlagGroup {
+	var groups []FlagGroup
+	for _, group := range nfs.Groups {
+		if nfs.FlagSets[name].Lookup(group.Flags[0]) != nil && !nfs.hidden(group) {
+			groups = append(groups, group)
 		}
+	}
+	return groups
+}
 
-		var buf bytes.Buffer
-		fmt.Fprintf(&buf, "\n%s flags:\n\n%s", strings.ToUpper(name[:1])+name[1:], wideFS.FlagUsagesWrapped(cols))
+// hidden reports whether a flag of group is hidden.
+func (nfs NamedFlagSets) hidden(group FlagGroup) bool {
+	for _, name := range group.Flags {
+		for _, fs := range nfs.FlagSets {
+			if f := fs.Lookup(name); f != nil && f.Hidden {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-		if cols > 24 {
-			i := strings.Index(buf.String(), zzz)
-			lines := strings.Split(buf.String()[:i], "\n")
-			fmt.Fprint(w, strings.Join(lines[:len(lines)-1], "\n"))
-			fmt.Fprintln(w)
-		} else {
-			fmt.Fprint(w, buf.String())
+func printGroups(w io.Writer, p *i18n.Printer, groups []FlagGroup, cols int) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, group := range groups {
+		for _, line := range Wrap(group.Localize(p), cols-len(helpIndent)) {
+			fmt.Fprintf(w, "%s%s\n", helpIndent, line)
 		}
 	}
 }
+
+// Merge appends the sections of other to nfs in their order. Flags of a section
+// that already exists in nfs are added to it, a flag already defined in the
+// section is kept as is. The groups of other are appended to the ones of nfs.
+func (nfs *NamedFlagSets) Merge(other NamedFlagSets) {
+	for _, name := range other.Order {
+		nfs.FlagSet(name).AddFlagSet(other.FlagSets[name])
+	}
+	nfs.Groups = append(nfs.Groups, other.Groups...)
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/lwm-galactic/utool/pkg/cli/flagtag"
+	"github.com/spf13/pflag"
+)
+
+// Tags read by StructFlags and ValidateStruct on the fields of an options
+// struct, see the flagtag package.
+const (
+	TagFlag       = flagtag.TagFlag
+	TagShort      = flagtag.TagShort
+	TagUsage      = flagtag.TagUsage
+	TagSection    = flagtag.TagSection
+	TagEnv        = flagtag.TagEnv
+	TagDefault    = flagtag.TagDefault
+	TagRequired   = flagtag.TagRequired
+	TagEnum       = flagtag.TagEnum
+	TagDeprecated = flagtag.TagDeprecated
+)
+
+// StructFlags builds the flag sets of the fields of the struct pointed to by
+// options from their tags, the flags are added to section unless they have a
+// section tag, see flagtag.AddFlags.
+func StructFlags(options interface{}, section string) (fss NamedFlagSets) {
+	flagtag.AddFlags(options, "", section, fss.FlagSet)
+	return fss
+}
+
+// ValidateStruct checks the required and enum tags of the fields of the struct
+// pointed to by options.
+func ValidateStruct(options interface{}) []error {
+	return flagtag.Validate(options)
+}
+
+// FlagEnv returns the environment variable set by the env tag of f.
+func FlagEnv(f *pflag.Flag) (string, bool) {
+	return flagtag.FlagEnv(f)
+}
@@ -0,0 +1,139 @@
+package cli_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+type logOptions struct {
+	Level string `mapstructure:"level" usage:"Minimum log level." enum:"debug,info,warn,error" default:"info"`
+}
+
+type structOptions struct {
+	OutputDir string        `mapstructure:"output-dir" short:"d" usage:"Output directory." default:"."`
+	Format    string        `mapstructure:"format" usage:"Output format." enum:"json,yaml"`
+	Token     string        `mapstructure:"token" usage:"API token." section:"auth" env:"TEST_TOKEN" required:"true"`
+	Timeout   time.Duration `mapstructure:"timeout" usage:"Timeout." default:"30s"`
+	Tags      []string      `flag:"tag" usage:"Tags."`
+	Log       logOptions    `mapstructure:"log"`
+	Files     []string      `mapstructure:"-"`
+	ignored   string
+}
+
+func Test_StructFlags(t *testing.T) {
+	o := &structOptions{}
+	fss := cli.StructFlags(o, "test")
+
+	assert.Equal(t, []string{"test", "auth"}, fss.Order)
+	fs := fss.FlagSets["test"]
+	for _, name := range []string{"output-dir", "format", "timeout", "tag", "log.level"} {
+		assert.NotNil(t, fs.Lookup(name), name)
+	}
+	assert.Nil(t, fs.Lookup("files"))
+	assert.Nil(t, fs.Lookup("ignored"))
+
+	assert.Equal(t, "d", fs.Lookup("output-dir").Shorthand)
+	assert.Equal(t, "Output directory.", fs.Lookup("output-dir").Usage)
+	assert.Equal(t, ".", o.OutputDir)
+	assert.Equal(t, 30*time.Second, o.Timeout)
+	assert.Equal(t, "info", o.Log.Level)
+	assert.Equal(t, "30s", fs.Lookup("timeout").DefValue)
+
+	values, ok := cli.CompletionValues(fs.Lookup("format"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"json", "yaml"}, values)
+
+	env, ok := cli.FlagEnv(fss.FlagSets["auth"].Lookup("token"))
+	assert.True(t, ok)
+	assert.Equal(t, "TEST_TOKEN", env)
+
+	assert.Nil(t, fs.Parse([]string{"-d", "out", "--tag", "a,b", "--log.level", "debug"}))
+	assert.Equal(t, "out", o.OutputDir)
+	assert.Equal(t, []string{"a", "b"}, o.Tags)
+	assert.Equal(t, "debug", o.Log.Level)
+}
+
+func Test_StructFlagsKeepValues(t *testing.T) {
+	o := &structOptions{OutputDir: "videos"}
+	fs := cli.StructFlags(o, "test").FlagSets["test"]
+
+	assert.Equal(t, "videos", o.OutputDir)
+	assert.Equal(t, "videos", fs.Lookup("output-dir").DefValue)
+}
+
+func Test_ValidateStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *structOptions
+		errs    []string
+	}{
+		{
+			name:    "valid",
+			options: &structOptions{Token: "t", Format: "json", Log: logOptions{Level: "warn"}},
+		},
+		{
+			name:    "invalid",
+			options: &structOptions{Format: "xml", Log: logOptions{Level: "trace"}},
+			errs: []string{
+				`invalid format "xml", must be one of json, yaml`,
+				"token is required",
+				`invalid log.level "trace", must be one of debug, info, warn, error`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			for _, err := range cli.ValidateStruct(tt.options) {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
+
+func Test_StructFlagsPanics(t *testing.T) {
+	assert.Panics(t, func() { cli.StructFlags(structOptions{}, "test") })
+	assert.Panics(t, func() {
+		cli.StructFlags(&struct {
+			C chan int `mapstructure:"c"`
+		}{}, "test")
+	})
+}
+
+func Test_ResetFlags(t *testing.T) {
+	o := &structOptions{}
+	fs := cli.StructFlags(o, "test").FlagSets["test"]
+	names := fs.StringSlice("name", []string{"x"}, "Names.")
+
+	assert.Nil(t, fs.Parse([]string{"-d", "out", "--tag", "a", "--name", "y"}))
+	cli.ResetFlags(fs)
+	assert.False(t, fs.Changed("output-dir"))
+	assert.Equal(t, ".", o.OutputDir)
+	assert.Nil(t, o.Tags)
+	assert.Equal(t, []string{"x"}, *names)
+
+	// slices are replaced by the first value of the next parse, not appended to.
+	assert.Nil(t, fs.Parse([]string{"--tag", "b", "--tag", "c", "--name", "z"}))
+	assert.Equal(t, []string{"b", "c"}, o.Tags)
+	assert.Equal(t, []string{"z"}, *names)
+}
+
+var goFlag = flag.String("test-reset-go-flag", "default", "Go flag.")
+
+func Test_ResetFlagsGoFlags(t *testing.T) {
+	fs := cli.StructFlags(&structOptions{}, "test").FlagSets["test"]
+	cli.InitFlags(fs)
+	assert.Nil(t, fs.Parse([]string{"--test-reset-go-flag", "parsed"}))
+	defer flag.Set("test-reset-go-flag", "default")
+
+	// the Go flags are shared by the process, they are not reset.
+	cli.ResetFlags(fs)
+	assert.False(t, fs.Changed("test-reset-go-flag"))
+	assert.Equal(t, "parsed", *goFlag)
+}
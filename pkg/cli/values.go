@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"reflect"
+
+	"github.com/lwm-galactic/utool/pkg/cli/flagtag"
+	"github.com/spf13/pflag"
+)
+
+// Typed flag values checking their input when the flags are parsed, see the
+// flagtag package.
+type (
+	CompletionHinter = flagtag.CompletionHinter
+	File             = flagtag.File
+	Dir              = flagtag.Dir
+	Enum             = flagtag.Enum
+	ByteSize         = flagtag.ByteSize
+	Rate             = flagtag.Rate
+	DurationRange    = flagtag.DurationRange
+	URL              = flagtag.URL
+)
+
+// Var adds the flag name with the given value to fs and sets the completion
+// hints of the value.
+func Var(fs *pflag.FlagSet, value pflag.Value, name, shorthand, usage string) {
+	flagtag.Var(fs, value, name, shorthand, usage)
+}
+
+// DecodeValueHook is a mapstructure decode hook setting the option fields
+// which implement pflag.Value with their Set method, see
+// flagtag.DecodeValueHook.
+func DecodeValueHook(from, to reflect.Value) (interface{}, error) {
+	return flagtag.DecodeValueHook(from, to)
+}
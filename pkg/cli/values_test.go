@@ -0,0 +1,109 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Values(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "a.pdf")
+	txt := filepath.Join(dir, "a.txt")
+	for _, file := range []string{pdf, txt} {
+		assert.Nil(t, os.WriteFile(file, nil, 0o644))
+	}
+
+	tests := []struct {
+		name  string
+		value pflag.Value
+		set   string
+		want  string
+		err   string
+	}{
+		{name: "file", value: &cli.File{Extensions: []string{"pdf"}}, set: pdf, want: pdf},
+		{name: "file extension", value: &cli.File{Extensions: []string{"pdf"}}, set: txt, err: "file " + txt + " must have a .pdf extension"},
+		{name: "file missing", value: &cli.File{}, set: filepath.Join(dir, "b.pdf"), err: "file " + filepath.Join(dir, "b.pdf") + " does not exist"},
+		{name: "file dir", value: &cli.File{}, set: dir, err: dir + " is a directory, not a file"},
+		{name: "dir", value: &cli.Dir{}, set: dir, want: dir},
+		{name: "dir missing", value: &cli.Dir{}, set: filepath.Join(dir, "out"), err: "directory " + filepath.Join(dir, "out") + " does not exist"},
+		{name: "dir create", value: &cli.Dir{Create: true}, set: filepath.Join(dir, "out", "docs"), want: filepath.Join(dir, "out", "docs")},
+		{name: "dir create in file", value: &cli.Dir{Create: true}, set: filepath.Join(pdf, "docs"),
+			err: "can not create directory " + filepath.Join(pdf, "docs") + ", " + pdf + " is not a directory"},
+		{name: "dir file", value: &cli.Dir{}, set: pdf, err: pdf + " is not a directory"},
+		{name: "enum", value: &cli.Enum{Values: []string{"mp4", "flv"}}, set: "flv", want: "flv"},
+		{name: "enum invalid", value: &cli.Enum{Values: []string{"mp4", "flv"}}, set: "avi", err: "must be one of mp4, flv"},
+		{name: "size", value: new(cli.ByteSize), set: "10MB", want: "10MB"},
+		{name: "size binary", value: new(cli.ByteSize), set: "1.5GiB", want: "1536MiB"},
+		{name: "size bytes", value: new(cli.ByteSize), set: "1500", want: "1500B"},
+		{name: "size overflow", value: new(cli.ByteSize), set: "8388608TiB", err: `invalid size "8388608TiB", use a number of bytes with an optional unit, e.g. 10MB or 1.5GiB`},
+		{name: "size invalid", value: new(cli.ByteSize), set: "10XB", err: `invalid size "10XB", use a number of bytes with an optional unit, e.g. 10MB or 1.5GiB`},
+		{name: "rate", value: new(cli.Rate), set: "2MB/s", want: "2MB/s"},
+		{name: "rate without unit", value: new(cli.Rate), set: "2MB", err: `invalid rate "2MB", use a size per second, e.g. 2MB/s`},
+		{name: "duration", value: &cli.DurationRange{Min: time.Second, Max: time.Hour}, set: "1m30s", want: "1m30s"},
+		{name: "duration too long", value: &cli.DurationRange{Min: time.Second, Max: time.Hour}, set: "2h", err: "must be between 1s and 1h0m0s"},
+		{name: "duration too short", value: &cli.DurationRange{Min: time.Second}, set: "10ms", err: "must be at least 1s"},
+		{name: "duration invalid", value: &cli.DurationRange{}, set: "soon", err: `invalid duration "soon", use e.g. 30s, 5m or 1h30m`},
+		{name: "url", value: &cli.URL{}, set: "https://www.bilibili.com/video/BV1", want: "https://www.bilibili.com/video/BV1"},
+		{name: "url scheme", value: &cli.URL{}, set: "ftp://example.com", err: "must be an http or https url"},
+		{name: "url schemes", value: &cli.URL{Schemes: []string{"ftp"}}, set: "ftp://example.com", want: "ftp://example.com"},
+		{name: "url relative", value: &cli.URL{}, set: "videos/BV1", err: "must be an http or https url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Set(tt.set)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, tt.value.String())
+		})
+	}
+}
+
+func Test_ValuesCompletion(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	cli.Var(fs, &cli.File{Extensions: []string{"pdf"}}, "file", "", "")
+	cli.Var(fs, &cli.Dir{}, "dir", "", "")
+	cli.Var(fs, &cli.Enum{Values: []string{"mp4", "flv"}}, "format", "", "")
+	cli.Var(fs, new(cli.ByteSize), "size", "", "")
+	cli.Var(fs, &cli.URL{}, "url", "", "")
+
+	assert.Equal(t, "enum", fs.Lookup("format").Value.Type())
+	assert.Equal(t, []string{"pdf"}, fs.Lookup("file").Annotations[cobra.BashCompFilenameExt])
+	assert.Contains(t, fs.Lookup("dir").Annotations, cobra.BashCompSubdirsInDir)
+	for name, want := range map[string][]string{"format": {"mp4", "flv"}, "size": nil, "url": {"http://", "https://"}} {
+		values, ok := cli.CompletionValues(fs.Lookup(name))
+		assert.True(t, ok, name)
+		assert.Equal(t, want, values, name)
+	}
+}
+
+func Test_ValuesParse(t *testing.T) {
+	var o struct {
+		Output cli.Dir      `mapstructure:"output"`
+		Limit  cli.Rate     `mapstructure:"limit"`
+		Size   cli.ByteSize `mapstructure:"size" default:"1MiB"`
+	}
+	fs := cli.StructFlags(&o, "test").FlagSets["test"]
+	fs.Init("test", pflag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+
+	assert.Equal(t, "1MiB", fs.Lookup("size").DefValue)
+	assert.EqualError(t, fs.Parse([]string{"--output", "missing"}), `invalid argument "missing" for "--output" flag: directory missing does not exist`)
+	assert.Nil(t, fs.Parse([]string{"--limit", "512KiB/s"}))
+	assert.Equal(t, cli.Rate(512<<10), o.Limit)
+	assert.Equal(t, cli.ByteSize(1<<20), o.Size)
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,61 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func complete(t *testing.T, args ...string) []string {
+	a := newTestApp()
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+	assert.Equal(t, 0, a.RunWithArgs(context.Background(), append([]string{"__complete"}, args...)))
+	return strings.Split(strings.TrimSpace(stdout.String()), "\n")
+}
+
+func Test_Completions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.pdf", "b.txt"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "pdf2docx file", args: []string{"pdf2docx", "--file", ""}, want: []string{"pdf", ":8"}},
+		{name: "pdf2docx file in input dir", args: []string{"pdf2docx", "--input-dir", dir, "--file", ""}, want: []string{filepath.Join(dir, "a.pdf"), ":4"}},
+		{name: "pdf2docx input dir", args: []string{"pdf2docx", "--input-dir", ""}, want: []string{":16"}},
+		{name: "pdf2docx output dir", args: []string{"pdf2docx", "--output-dir", ""}, want: []string{":16"}},
+		{name: "download file", args: []string{"download", "--file", ""}, want: []string{"txt", ":8"}},
+		{name: "pdf2docx convert args", args: []string{"pdf2docx", "convert", "a.pdf", ""}, want: []string{"pdf", ":8"}},
+		{name: "download output dir", args: []string{"download", "--output-dir", ""}, want: []string{":16"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, complete(t, tt.args...))
+		})
+	}
+}
+
+func Test_CompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		a := newTestApp()
+		var stdout bytes.Buffer
+		a.Command().SetOut(&stdout)
+		assert.Equal(t, 0, a.RunWithArgs(context.Background(), []string{"completion", shell}), shell)
+		assert.Contains(t, stdout.String(), "tool", shell)
+	}
+
+	a := newTestApp()
+	a.Command().SetErr(&bytes.Buffer{})
+	assert.Equal(t, 1, a.RunWithArgs(context.Background(), []string{"completion", "tcsh"}))
+}
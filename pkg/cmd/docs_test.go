@@ -0,0 +1,100 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// update rewrites the golden files, run `go test ./pkg/cmd -run Golden -update`
+// after an intended change of the help or of the docs.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// newDocsApp returns the app of cmd/main.go, with the configuration file so
+// that the docs have the environment variables and the configuration keys.
+func newDocsApp() *app.App {
+	return app.NewApp("tool", "tool",
+		app.WithDescription("cmd.description"),
+		app.WithCommands(cmd.NewUpdateCommand(), cmd.NewPdf2DocxCommand(), cmd.NewInitCommand(), cmd.NewDownloadCommand()),
+	)
+}
+
+// assertGolden compares got with the golden file, which is rewritten by -update.
+func assertGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		assert.NoError(t, os.WriteFile(golden, got, 0o644))
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if assert.NoError(t, err, "run go test -update to create %s", golden) {
+		assert.Equal(t, string(want), string(got), "%s changed, run go test -update if it is intended", golden)
+	}
+}
+
+func Test_DocsGolden(t *testing.T) {
+	dir := t.TempDir()
+	a := newDocsApp()
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"docs", "--dir", dir}))
+
+	files := strings.Fields(stdout.String())
+	assert.Contains(t, files, filepath.Join(dir, "tool.html"))
+	assert.Contains(t, files, filepath.Join(dir, "tool-pdf2docx-convert.1"))
+	assert.Contains(t, files, filepath.Join(dir, "tool-download.md"))
+	assert.NotContains(t, files, filepath.Join(dir, "tool-docs.md"))
+	for _, file := range files {
+		got, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assertGolden(t, filepath.Join("testdata", "docs", filepath.Base(file)), got)
+	}
+}
+
+func Test_HelpGolden(t *testing.T) {
+	for _, path := range [][]string{
+		{},
+		{"download"},
+		{"init"},
+		{"pdf2docx"},
+		{"pdf2docx", "convert"},
+		{"pdf2docx", "gui"},
+		{"update"},
+	} {
+		name := strings.Join(append([]string{"tool"}, path...), "-")
+		t.Run(name, func(t *testing.T) {
+			a := newDocsApp()
+			var stdout bytes.Buffer
+			a.Command().SetOut(&stdout)
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), append(path, "--help")))
+			assertGolden(t, filepath.Join("testdata", "help", name+".txt"), stdout.Bytes())
+		})
+	}
+}
+
+func Test_DocsInvalidFormat(t *testing.T) {
+	a := newDocsApp()
+	var stderr bytes.Buffer
+	a.Command().SetErr(&stderr)
+	assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(context.Background(), []string{"docs", "--dir", t.TempDir(), "--format", "pdf"}))
+	assert.Contains(t, stderr.String(), `invalid --format format "pdf"`)
+}
+
+func Test_DocsDryRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	a := newDocsApp()
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+	assert.Equal(t, app.ExitCodeDryRunPending, a.RunWithArgs(context.Background(), []string{"docs", "--dir", dir, "--dry-run"}))
+	assert.Contains(t, stdout.String(), filepath.Join(dir, "tool.html"))
+	assert.NoDirExists(t, dir)
+}
@@ -1,103 +1,140 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/lwm-galactic/utool/pkg/app"
 	"github.com/lwm-galactic/utool/pkg/cli"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/lwm-galactic/utool/pkg/log"
-	"github.com/spf13/pflag"
-	"os"
-	"os/exec"
+	"github.com/lwm-galactic/utool/pkg/runner"
 	"strings"
 )
 
 type DownloadOptions struct {
-	Url       string `mapstructure:"url"`
-	File      string `mapstructure:"file"`
-	OutputDir string `mapstructure:"output-dir"`
+	Url       cli.URL  `mapstructure:"url" usage:"cmd.download.flag.url"`
+	File      cli.File `mapstructure:"file" usage:"cmd.download.flag.file"`
+	OutputDir cli.Dir  `mapstructure:"output-dir" usage:"cmd.download.flag.output_dir"`
+	// URLs are the download addresses given as args.
+	URLs []string `mapstructure:"-"`
 }
 
-func (o *DownloadOptions) Validate() []error {
-	var errs []error
-	if o.Url == "" && o.File == "" {
-		errs = append(errs, fmt.Errorf("url or file is required"))
-	}
-	return errs
+// SetArgs binds the download addresses given as args.
+func (o *DownloadOptions) SetArgs(args []string) error {
+	o.URLs = args
+	return nil
 }
 
-// AddFlags adds flags related to features for a specific api server to the
-// specified FlagSet.
-func (o *DownloadOptions) AddFlags(fs *pflag.FlagSet) {
-	if fs == nil {
-		return
-	}
-
-	fs.StringVar(&o.OutputDir, "output-dir", o.OutputDir,
-		"设置下载输出文件夹")
-
-	fs.StringVar(&o.File, "file", o.File, "指定一个文件,一行是一个下载地址 用于批量下载")
+// Validate has nothing to check, the flags check their values and the flag
+// groups which of them are given.
+func (o *DownloadOptions) Validate() []error { return nil }
 
-	fs.StringVar(&o.Url, "url", o.Url, "指定一个下载地址")
+// validateURL checks that value is an absolute http or https url.
+func validateURL(value string) error {
+	return (&cli.URL{}).Set(value)
 }
 
+// Flags builds the flags from the tags of the options, --file is completed
+// with the txt files although any file is accepted. Exactly one of --url, the
+// url args or --file gives the addresses to download.
 func (o *DownloadOptions) Flags() (fss cli.NamedFlagSets) {
-	o.AddFlags(fss.FlagSet("download"))
+	fss = cli.StructFlags(o, "download")
+	cli.MarkFileCompletion(fss.FlagSet("download"), "file", "txt")
+	fss.MarkOneRequired("url", "file")
+	fss.MarkMutuallyExclusive("url", "file")
 	return fss
 }
 
 func NewDownloadCommand() *app.Command {
-	return app.NewCommand("download", "用于下载 bilibili | youtube 的视频工具", app.WithCommandRunFunc(downloadRun), app.WithCommandOptions(NewDownloadOptions()))
+	return app.NewCommand("download", "cmd.download.short", app.WithCommandResultFunc(downloadRun), app.WithCommandOptions(NewDownloadOptions()),
+		app.WithAliases("dl"),
+		app.WithCommandArgs(app.Arg{Name: "url", Usage: "cmd.download.arg.url", Optional: true, Variadic: true, Validate: validateURL}),
+		app.WithCommandMiddleware(app.RequireCommands("you-get")),
+		app.WithLong("cmd.download.long"),
+		app.WithExamples(
+			"tool download https://www.bilibili.com/video/BV1xx411c7mD https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			"tool download --url https://www.bilibili.com/video/BV1xx411c7mD",
+			"tool download --file urls.txt --output-dir videos",
+		))
 }
 func NewDownloadOptions() *DownloadOptions {
 	return &DownloadOptions{
-		OutputDir: ".",
+		OutputDir: cli.Dir{Path: ".", Create: true},
+	}
+}
+
+// downloadResult is the result of download.
+type downloadResult struct {
+	URLs      []string `json:"urls,omitempty"`
+	File      string   `json:"file,omitempty"`
+	OutputDir string   `json:"outputDir"`
+	// Status is downloaded or failed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const statusDownloaded = "downloaded"
+
+func (r downloadResult) Text() string {
+	return r.Localize(nil)
+}
+
+// Localize returns the text of the result in the language of p.
+func (r downloadResult) Localize(p *i18n.Printer) string {
+	source := strings.Join(r.URLs, " ")
+	if r.File != "" {
+		source = r.File
+	}
+	if r.Status == statusFailed {
+		return p.T("cmd.download.result.failed", source, r.Error) + "\n"
 	}
+	return p.T("cmd.download.result.downloaded", source, r.OutputDir) + "\n"
 }
 
-func downloadRun(option app.CliOptions) error {
+func downloadRun(ctx context.Context, option app.CliOptions) (interface{}, error) {
 	opts, ok := option.(*DownloadOptions)
 	if !ok {
-		return fmt.Errorf("downloadRun: invalid options")
+		return nil, fmt.Errorf("downloadRun: invalid options")
 	}
 
 	// 确保输出目录存在
-	if opts.OutputDir != "" {
-		err := os.MkdirAll(opts.OutputDir, os.ModePerm)
+	if opts.OutputDir.Path != "" {
+		err := mkdirAll(ctx, opts.OutputDir.Path)
 		if err != nil {
-			return fmt.Errorf("创建输出目录失败: %v", err)
+			return nil, i18n.Errorf("cmd.download.mkdir_failed", err)
 		}
 	}
 
 	// 构建 you-get 命令参数
 	cmdArgs := []string{}
-	if opts.OutputDir != "" {
-		cmdArgs = append(cmdArgs, "-o", opts.OutputDir)
+	if opts.OutputDir.Path != "" {
+		cmdArgs = append(cmdArgs, "-o", opts.OutputDir.Path)
 	}
 
+	result := downloadResult{OutputDir: opts.OutputDir.Path, Status: statusDownloaded}
 	// 判断是通过 URL 下载还是通过文件下载
-	if opts.Url != "" {
-		cmdArgs = append(cmdArgs, opts.Url)
-	} else if opts.File != "" {
-		cmdArgs = append(cmdArgs, "-i", opts.File)
+	if opts.Url.URL != nil || len(opts.URLs) > 0 {
+		if opts.Url.URL != nil {
+			result.URLs = append(result.URLs, opts.Url.String())
+		}
+		result.URLs = append(result.URLs, opts.URLs...)
+		cmdArgs = append(cmdArgs, result.URLs...)
+	} else if opts.File.Path != "" {
+		result.File = opts.File.Path
+		cmdArgs = append(cmdArgs, "-i", opts.File.Path)
 	} else {
-		return fmt.Errorf("必须指定 url 或 file 参数")
+		return nil, i18n.Errorf("cmd.download.source_required")
 	}
 
 	// 打印正在执行的命令
-	log.Infof("执行命令: you-get %s", strings.Join(cmdArgs, " "))
-
-	// 创建命令
-	cmd := exec.Command("you-get", cmdArgs...)
+	log.Info(i18n.FromContext(ctx).T("cmd.download.running", strings.Join(cmdArgs, " ")))
 
-	// 设置标准输出和错误输出流（实时输出）
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// 执行命令
-	err := cmd.Run()
+	// 执行命令, 输出实时打印到控制台
+	_, err := runExternal(ctx, runner.Command{Name: "you-get", Args: cmdArgs})
 	if err != nil {
-		return fmt.Errorf("you-get 执行失败: %v", err)
+		result.Status, result.Error = statusFailed, err.Error()
+		return result, dependencyError(ctx, "you-get", i18n.Errorf("cmd.download.failed", err))
 	}
 
-	return nil
+	return result, nil
 }
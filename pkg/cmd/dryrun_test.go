@@ -0,0 +1,75 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DryRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "a.pdf")
+	assert.Nil(t, os.WriteFile(pdf, nil, 0o644))
+	videos := filepath.Join(dir, "videos")
+
+	type action struct {
+		Command []string `json:"command"`
+		Creates []string `json:"creates"`
+	}
+	tests := []struct {
+		name string
+		args []string
+		want []action
+	}{
+		{
+			name: "pdf2docx",
+			args: []string{"pdf2docx", pdf, "--output-dir", dir},
+			want: []action{{
+				Command: []string{"pdf2docx", "convert", pdf, filepath.Join(dir, "a.docx")},
+				Creates: []string{filepath.Join(dir, "a.docx")},
+			}},
+		},
+		{
+			name: "download",
+			args: []string{"download", "https://www.bilibili.com/video/BV1", "--output-dir", videos},
+			want: []action{
+				{Creates: []string{videos}},
+				{Command: []string{"you-get", "-o", videos, "https://www.bilibili.com/video/BV1"}},
+			},
+		},
+		{
+			name: "init",
+			args: []string{"init"},
+			want: []action{
+				{Command: []string{"pip", "install", "pdf2docx", "-i", "https://pypi.tuna.tsinghua.edu.cn/simple"}},
+				{Command: []string{"pip", "install", "you-get", "-i", "https://pypi.tuna.tsinghua.edu.cn/simple"}},
+				{Creates: []string{filepath.Join(home, ".tool", "require.json")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp()
+			var stdout bytes.Buffer
+			a.Command().SetOut(&stdout)
+
+			assert.Equal(t, app.ExitCodeDryRunPending, a.RunWithArgs(context.Background(), append(tt.args, "--dry-run", "-o", "json")))
+			var plan struct {
+				Actions []action `json:"actions"`
+			}
+			assert.Nil(t, json.Unmarshal(stdout.Bytes(), &plan))
+			assert.Equal(t, tt.want, plan.Actions)
+		})
+	}
+	assert.NoDirExists(t, videos)
+	assert.NoDirExists(t, filepath.Join(home, ".tool"))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/runner"
+)
+
+// runExternal runs c with the runner of ctx. The output of the command is
+// logged when the results are printed as json or yaml so that it does not mix
+// with them.
+func runExternal(ctx context.Context, c runner.Command) (*runner.Result, error) {
+	// 结果以 json 或 yaml 输出时, 外部命令的输出不能混入结果
+	if format := app.OutputFormat(ctx); format == app.OutputJSON || format == app.OutputYAML {
+		c.Output = runner.Log
+	}
+	return runner.FromContext(ctx).Run(ctx, c)
+}
+
+// mkdirAll creates dir, a dry run records it instead if it does not exist.
+func mkdirAll(ctx context.Context, dir string) error {
+	if app.IsDryRun(ctx) {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			app.RecordAction(ctx, app.Action{Creates: []string{dir}})
+		}
+		return nil
+	}
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// dependencyError attaches a hint to run the init command when the external
+// tool name is not installed, the hint is in the language of the run in ctx.
+func dependencyError(ctx context.Context, name string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return app.NewExitError(app.ExitCodeError,
+			i18n.FromContext(ctx).T("cmd.dependency_hint", name), err)
+	}
+	return err
+}
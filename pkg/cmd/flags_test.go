@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestApp() *app.App {
+	return app.NewApp("tool", "tool",
+		app.WithNoConfig(),
+		app.WithCommands(cmd.NewUpdateCommand(), cmd.NewPdf2DocxCommand(), cmd.NewInitCommand(), cmd.NewDownloadCommand()),
+	)
+}
+
+func Test_CommandFlags(t *testing.T) {
+	// the paths given to the flags are checked when the flags are parsed.
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"in", "out"} {
+		assert.Nil(t, os.Mkdir(dir, 0o755))
+	}
+	for _, file := range []string{"urls.txt", "a.pdf"} {
+		assert.Nil(t, os.WriteFile(file, nil, 0o644))
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		flags map[string]string
+	}{
+		{
+			name:  "download url",
+			args:  []string{"download", "--url", "https://www.bilibili.com/video/BV1", "--output-dir", "videos"},
+			flags: map[string]string{"url": "https://www.bilibili.com/video/BV1", "output-dir": "videos", "file": ""},
+		},
+		{
+			name:  "download file",
+			args:  []string{"download", "--file=urls.txt"},
+			flags: map[string]string{"file": "urls.txt", "output-dir": "."},
+		},
+		{
+			name:  "pdf2docx batch",
+			args:  []string{"pdf2docx", "--input-dir", "in", "--output-dir", "out"},
+			flags: map[string]string{"input-dir": "in", "output-dir": "out"},
+		},
+		{
+			name:  "pdf2docx file",
+			args:  []string{"pdf2docx", "--file", "a.pdf"},
+			flags: map[string]string{"file": "a.pdf"},
+		},
+		{
+			name: "init",
+			args: []string{"init"},
+		},
+		{
+			name:  "update",
+			args:  []string{"update", "--base-url", "http://localhost:8080/releases", "--check"},
+			flags: map[string]string{"base-url": "http://localhost:8080/releases", "check": "true", "rollback": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rest, err := newTestApp().Command().Find(tt.args)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.args[0], c.Name())
+			assert.Nil(t, c.ParseFlags(rest))
+
+			for name, value := range tt.flags {
+				f := c.Flags().Lookup(name)
+				if assert.NotNil(t, f, name) {
+					assert.Equal(t, value, f.Value.String(), name)
+				}
+			}
+		})
+	}
+}
+
+func Test_CommandUnknownFlag(t *testing.T) {
+	for _, args := range [][]string{
+		{"download", "--input-dir", "in"},
+		{"pdf2docx", "--url", "https://example.com"},
+		{"init", "--url", "https://example.com"},
+		{"update", "--file", "a.pdf"},
+	} {
+		c, rest, err := newTestApp().Command().Find(args)
+		assert.Nil(t, err)
+		assert.NotNil(t, c.ParseFlags(rest), args)
+	}
+}
@@ -1,12 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/lwm-galactic/utool/pkg/runner"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
@@ -26,54 +27,76 @@ func init() {
 }
 
 func NewInitCommand() *app.Command {
-	return app.NewCommand("init", "to init tool like download the dependence", app.WithCommandRunFunc(InitCommandRun))
+	return app.NewCommand("init", "cmd.init.short", app.WithCommandResultFunc(InitCommandRun),
+		app.WithLong("cmd.init.long"))
 }
 
-func InitCommandRun(option app.CliOptions) error {
+// packageStatus is the state of a dependency after init.
+type packageStatus struct {
+	Name string `json:"name"`
+	// Status is installed, already-installed or failed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const (
+	statusInstalled        = "installed"
+	statusAlreadyInstalled = "already-installed"
+)
+
+// InitCommandRun installs the dependencies, the result is the status of every
+// package and is printed as a table in the text format.
+func InitCommandRun(ctx context.Context, option app.CliOptions) (interface{}, error) {
 	log.Info("InitCommand call")
+	result := []packageStatus{}
 	for require, list := range requireList {
 		switch require {
 		case Python:
-			return initPython(list)
+			packages, err := initPython(ctx, list)
+			result = append(result, packages...)
+			if err != nil {
+				return result, err
+			}
 		}
 	}
 
 	// log.Info("get cookie from web")
-	log.Info("init success")
-	return nil
+	log.Info(i18n.FromContext(ctx).T("cmd.init.done"))
+	return result, nil
 }
 
-func initPython(pkgs []string) error {
+func initPython(ctx context.Context, pkgs []string) ([]packageStatus, error) {
 	log.Info("initPython call")
-	installPkg, _ := loadPythonInstallPkg()
+	p := i18n.FromContext(ctx)
+	installPkg, _ := loadPythonInstallPkg(p)
 	log.Infof("need pkg: %v", pkgs)
 	log.Infof("installPkg: %v", installPkg)
 
+	var packages []packageStatus
+	for _, pkg := range installPkg {
+		packages = append(packages, packageStatus{Name: pkg, Status: statusAlreadyInstalled})
+	}
 	pkgs = removeSliceElements(pkgs, installPkg)
 	if len(pkgs) == 0 {
-		log.Info("all packages already installed")
-		return nil
+		log.Info(p.T("cmd.init.installed"))
+		return packages, nil
 	}
 	for _, pkg := range pkgs {
-		cmd := exec.Command(PIP, "install", pkg, "-i", Hasten)
-		// 设置 stdout 和 stderr 的管道
-		cmd.Stdout = os.Stdout // 直接输出到控制台
-		cmd.Stderr = os.Stderr // 错误输出也直接输出到控制台
-
-		err := cmd.Run()
+		_, err := runExternal(ctx, runner.Command{Name: PIP, Args: []string{"install", pkg, "-i", Hasten}})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error init python for %v\n", err)
-			return err
+			log.Error(p.T("cmd.init.pip_failed", err))
+			return append(packages, packageStatus{Name: pkg, Status: statusFailed, Error: err.Error()}), err
 		}
 		installPkg = append(installPkg, pkg)
+		packages = append(packages, packageStatus{Name: pkg, Status: statusInstalled})
 	}
 	install := make(map[string][]string)
 	install[Python] = installPkg
-	err := saveAllReadyInstall(install)
+	err := saveAllReadyInstall(ctx, install)
 	if err != nil {
-		return err
+		return packages, err
 	}
-	return nil
+	return packages, nil
 }
 
 func removeSliceElements(a, b []string) []string {
@@ -96,21 +119,19 @@ func removeSliceElements(a, b []string) []string {
 
 	return result
 }
-func loadPythonInstallPkg() ([]string, error) {
+
+// loadPythonInstallPkg returns the installed python packages, the errors are
+// logged in the language of p.
+func loadPythonInstallPkg(p *i18n.Printer) ([]string, error) {
 	homeDir, _ := os.UserHomeDir()
 	folderPath := filepath.Join(homeDir, ".tool")
 	log.Infof(folderPath)
-	err := os.MkdirAll(folderPath, os.ModePerm)
-	if err != nil {
-		log.Errorf("create work dir err: %v", err.Error())
-		return nil, err
-	}
-	// 构建 require.json 路径
+	// 构建 require.json 路径, 目录由 saveAllReadyInstall 创建
 	jsonFilePath := filepath.Join(folderPath, Configuration)
 	// 读取文件内容
 	data, err := os.ReadFile(jsonFilePath)
 	if err != nil {
-		log.Errorf("读取 require.json 失败: %v", err)
+		log.Error(p.T("cmd.init.read_failed", Configuration, err))
 		return nil, err
 	}
 
@@ -118,34 +139,35 @@ func loadPythonInstallPkg() ([]string, error) {
 	var result map[string][]string
 	err = json.Unmarshal(data, &result)
 	if err != nil {
-		log.Errorf("解析 require.json 失败: %v", err)
+		log.Error(p.T("cmd.init.parse_failed", Configuration, err))
 		return nil, err
 	}
 
 	return result[Python], nil
 }
-func saveAllReadyInstall(require map[string][]string) error {
+func saveAllReadyInstall(ctx context.Context, require map[string][]string) error {
 	homeDir, _ := os.UserHomeDir()
 	folderPath := filepath.Join(homeDir, ".tool")
 	log.Infof(folderPath)
+	// 构建 require.json 路径
+	jsonFilePath := filepath.Join(folderPath, Configuration)
+	if app.IsDryRun(ctx) {
+		app.RecordAction(ctx, app.Action{Creates: []string{jsonFilePath}})
+		return nil
+	}
 	err := os.MkdirAll(folderPath, os.ModePerm)
 	if err != nil {
-		log.Errorf("create work dir err: %v", err.Error())
-		return err
+		return i18n.Errorf("cmd.init.mkdir_failed", err)
 	}
-	// 构建 require.json 路径
-	jsonFilePath := filepath.Join(folderPath, Configuration)
 	// 将 requireList 转换为 JSON 数据
 	data, err := json.MarshalIndent(require, "", "    ")
 	if err != nil {
-		log.Fatalf("JSON 序列化失败: %v", err)
-		return err
+		return i18n.Errorf("cmd.init.marshal_failed", err)
 	}
 	// 写入文件
 	err = os.WriteFile(jsonFilePath, data, 0644)
 	if err != nil {
-		log.Fatalf("写入 require.json 失败: %v", err)
-		return err
+		return i18n.Errorf("cmd.init.write_failed", Configuration, err)
 	}
 	return nil
 }
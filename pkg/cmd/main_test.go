@@ -0,0 +1,14 @@
+package cmd_test
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests in the Fallback language and without colors, whatever
+// the locale and the terminal of the machine running them.
+func TestMain(m *testing.M) {
+	os.Setenv("LC_ALL", "C")
+	os.Setenv("NO_COLOR", "1")
+	os.Exit(m.Run())
+}
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/lwm-galactic/utool/pkg/cli"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/lwm-galactic/utool/pkg/app"
-	"github.com/spf13/pflag"
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/lwm-galactic/utool/pkg/runner"
+	"github.com/spf13/cobra"
 	"os"
 	"strings"
 )
@@ -20,119 +23,217 @@ const (
 )
 
 type Pdf2DocxOptions struct {
-	InputDir  string `mapstructure:"input-dir"`
-	OutputDir string `mapstructure:"output-dir"`
-	File      string `mapstructure:"file"`
-	GUI       bool   `mapstructure:"gui"`
+	InputDir  cli.Dir  `mapstructure:"input-dir" usage:"cmd.pdf2docx.flag.input_dir"`
+	OutputDir cli.Dir  `mapstructure:"output-dir" usage:"cmd.pdf2docx.flag.output_dir"`
+	File      cli.File `mapstructure:"file" usage:"cmd.pdf2docx.flag.file"`
+	GUI       bool     `mapstructure:"gui" usage:"cmd.pdf2docx.flag.gui" deprecated:"cmd.pdf2docx.flag.gui_deprecated"`
+	// Files are the pdf files given as args, only they are converted when set.
+	Files []string `mapstructure:"-"`
 }
 
-func (o *Pdf2DocxOptions) Validate() []error {
-	var errs []error
-	if o.InputDir != "" {
-		if _, err := os.Stat(o.InputDir); os.IsNotExist(err) {
-			errs = append(errs, fmt.Errorf("input directory does not exist: %s", o.InputDir))
-		}
-	}
-	if o.OutputDir != "" {
-		if _, err := os.Stat(o.OutputDir); os.IsNotExist(err) {
-			errs = append(errs, fmt.Errorf("output directory does not exist: %s", o.OutputDir))
-		}
-	}
-	if o.File != "" {
-		// 检查是否以 .pdf 结尾
-		if !strings.HasSuffix(strings.ToLower(o.File), ".pdf") {
-			errs = append(errs, fmt.Errorf("file must have a .pdf extension"))
+// SetArgs binds the pdf files given as args.
+func (o *Pdf2DocxOptions) SetArgs(args []string) error {
+	o.Files = args
+	return nil
+}
+
+// Validate has nothing to check, the flags and the args check their values.
+func (o *Pdf2DocxOptions) Validate() []error { return nil }
+
+// pdfFileArg is the pdf files arg of pdf2docx and pdf2docx convert.
+var pdfFileArg = app.Arg{
+	Name:     "file.pdf",
+	Usage:    "cmd.pdf2docx.arg.file",
+	Optional: true,
+	Variadic: true,
+	Validate: func(value string) error {
+		if !strings.HasSuffix(strings.ToLower(value), ".pdf") {
+			return i18n.Errorf("cmd.pdf2docx.arg.extension")
 		}
-	}
-	return errs
+		return nil
+	},
+	Complete: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return []cobra.Completion{"pdf"}, cobra.ShellCompDirectiveFilterFileExt
+	},
 }
 
 func NewPdf2DocxOptions() *Pdf2DocxOptions {
 	return &Pdf2DocxOptions{
-		InputDir:  ".",
-		OutputDir: ".",
-		File:      "",
+		InputDir:  cli.Dir{Path: "."},
+		OutputDir: cli.Dir{Path: "."},
+		File:      cli.File{Extensions: []string{"pdf"}},
 		GUI:       false,
 	}
 }
 
-// AddFlags adds flags related to features for a specific api server to the
-// specified FlagSet.
-func (o *Pdf2DocxOptions) AddFlags(fs *pflag.FlagSet) {
-	if fs == nil {
-		return
+// Flags builds the flags from the tags of the options. --gui is deprecated in
+// favour of the gui subcommand, it opens the graphical interface instead of
+// converting files so it can not be used with the flags selecting them.
+func (o *Pdf2DocxOptions) Flags() (fss cli.NamedFlagSets) {
+	fss = cli.StructFlags(o, "pdf2docx")
+	fss.MarkMutuallyExclusive("gui", "file")
+	fss.MarkMutuallyExclusive("gui", "input-dir")
+	return fss
+}
+
+// FlagCompletions completes --file with the pdf files of --input-dir when it
+// is given, with the pdf files of the current completion path otherwise.
+func (o *Pdf2DocxOptions) FlagCompletions() map[string]cobra.CompletionFunc {
+	return map[string]cobra.CompletionFunc{
+		"file": func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if !cmd.Flags().Changed("input-dir") {
+				return []cobra.Completion{"pdf"}, cobra.ShellCompDirectiveFilterFileExt
+			}
+			pdfFiles, err := readPdfFile(i18n.FromContext(cmd.Context()), o.InputDir.Path)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			var completions []cobra.Completion
+			for _, pdfFile := range pdfFiles {
+				if strings.HasPrefix(pdfFile, toComplete) {
+					completions = append(completions, pdfFile)
+				}
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		},
 	}
+}
 
-	fs.StringVar(&o.InputDir, "input-dir", o.InputDir,
-		"设置 pdf2docx 要转换 pdf文件 所在的文件夹 (批量操作将该文件夹下的所有 *.pdf 文件转换成 *.docx)")
+func NewPdf2DocxCommand() *app.Command {
+	return app.NewCommand("pdf2docx", "cmd.pdf2docx.short", app.WithCommandResultFunc(pdf2docxRun), app.WithCommandOptions(NewPdf2DocxOptions()),
+		app.WithSubCommands(newPdf2DocxConvertCommand(), newPdf2DocxGUICommand()),
+		app.WithCommandMiddleware(app.RequireCommands(BaseCommandName)),
+		app.WithCommandArgs(pdfFileArg),
+		app.WithExamples(
+			"tool pdf2docx report.pdf slides.pdf",
+			"tool pdf2docx --file report.pdf",
+			"tool pdf2docx convert --input-dir pdfs --output-dir docs",
+			"tool pdf2docx gui",
+		))
+}
 
-	fs.StringVar(&o.OutputDir, "output-dir", o.OutputDir,
-		"设置 pdf2docx 转换 docx文件后 所在的文件夹")
+func newPdf2DocxConvertCommand() *app.Command {
+	return app.NewCommand(Convert, "cmd.pdf2docx.convert.short", app.WithCommandResultFunc(pdf2docxRun), app.WithCommandOptions(NewPdf2DocxOptions()),
+		app.WithCommandArgs(pdfFileArg))
+}
 
-	fs.StringVar(&o.File, "file", o.File, "指定一个pdf文件转换成docx")
+func newPdf2DocxGUICommand() *app.Command {
+	return app.NewCommand(GUI, "cmd.pdf2docx.gui.short", app.WithCommandRunContextFunc(func(ctx context.Context, option app.CliOptions) error {
+		return pdf2docxGUI(ctx)
+	}))
+}
 
-	fs.BoolVar(&o.GUI, "gui", o.GUI, "是否使用图形化界面操作 true or false")
+func pdf2docxGUI(ctx context.Context) error {
+	_, err := runExternal(ctx, runner.Command{Name: BaseCommandName, Args: []string{GUI}})
+	if err != nil {
+		log.Errorf("pdf2docxRun err: %v", err)
+		return dependencyError(ctx, BaseCommandName, err)
+	}
+
+	return nil
 }
 
-func (o *Pdf2DocxOptions) Flags() (fss cli.NamedFlagSets) {
-	o.AddFlags(fss.FlagSet("pdf2docx"))
-	return fss
+// convertResult is the result of pdf2docx, a file per converted pdf.
+type convertResult struct {
+	Files []convertedFile `json:"files"`
 }
 
-func NewPdf2DocxCommand() *app.Command {
-	return app.NewCommand("pdf2docx", "将 pdf 文件转换成 docx", app.WithCommandRunFunc(pdf2docxRun), app.WithCommandOptions(NewPdf2DocxOptions()))
+type convertedFile struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	// Status is converted or failed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const (
+	statusConverted = "converted"
+	statusFailed    = "failed"
+)
+
+func (r convertResult) Text() string {
+	return r.Localize(nil)
+}
+
+// Localize returns the text of the result in the language of p.
+func (r convertResult) Localize(p *i18n.Printer) string {
+	var b strings.Builder
+	for _, f := range r.Files {
+		if f.Status == statusFailed {
+			fmt.Fprintln(&b, p.T("cmd.pdf2docx.result.failed", f.Input, f.Error))
+			continue
+		}
+		fmt.Fprintln(&b, p.T("cmd.pdf2docx.result.converted", f.Input, f.Output))
+	}
+	return b.String()
+}
+
+func (r convertResult) Table() ([]string, [][]string) {
+	return r.LocalizeTable(nil)
+}
+
+// LocalizeTable returns a row per file, the header is in the language of p.
+func (r convertResult) LocalizeTable(p *i18n.Printer) ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Files))
+	for _, f := range r.Files {
+		rows = append(rows, []string{f.Input, f.Output, f.Status, f.Error})
+	}
+	return strings.Split(p.T("cmd.pdf2docx.result.header"), "\t"), rows
 }
 
-func pdf2docxRun(option app.CliOptions) error {
+func pdf2docxRun(ctx context.Context, option app.CliOptions) (interface{}, error) {
 	opts, ok := option.(*Pdf2DocxOptions)
 	if !ok {
-		return fmt.Errorf("pdf2docxRun option is invalid")
+		return nil, fmt.Errorf("pdf2docxRun option is invalid")
 	}
 
 	if opts.GUI {
-		cmd := exec.Command(BaseCommandName, GUI)
-		err := cmd.Run()
-		if err != nil {
-			fmt.Printf("pdf2docxRun err: %v\n", err)
-			return err
-		}
-
-		return nil
+		return nil, pdf2docxGUI(ctx)
 	}
 
 	var pdfFiles []string
 	var err error
-	if opts.InputDir != "" {
-		pdfFiles, err = readPdfFile(opts.InputDir)
+	if len(opts.Files) > 0 {
+		pdfFiles = append(pdfFiles, opts.Files...)
+	} else if opts.InputDir.Path != "" {
+		pdfFiles, err = readPdfFile(i18n.FromContext(ctx), opts.InputDir.Path)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	if opts.File != "" {
-		if _, err := os.Stat(opts.File); os.IsNotExist(err) {
-			return fmt.Errorf("指定的文件不存在: %s", opts.File)
+	if opts.File.Path != "" {
+		pdfFiles = append(pdfFiles, opts.File.Path)
+	}
+	for _, pdfFile := range pdfFiles {
+		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
+			return nil, i18n.Errorf("cmd.pdf2docx.file_not_exist", pdfFile)
 		}
-		pdfFiles = append(pdfFiles, opts.File)
 	}
 
+	result := convertResult{Files: []convertedFile{}}
+	failed := 0
 	for _, pdfFile := range pdfFiles {
-		cmd := exec.Command(BaseCommandName, Convert, pdfFile,
-			filepath.Join(opts.OutputDir, getFileName(pdfFile)))
-		// 设置 stdout 和 stderr 的管道
-		cmd.Stdout = os.Stdout // 直接输出到控制台
-		cmd.Stderr = os.Stderr // 错误输出也直接输出到控制台
+		// 被中断时不再处理剩余的文件
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		output := filepath.Join(opts.OutputDir.Path, getFileName(pdfFile))
+		cmd := runner.Command{Name: BaseCommandName, Args: []string{Convert, pdfFile, output}, Creates: []string{output}}
 
-		fmt.Printf("Processing: %s\n", pdfFile)
+		log.Info(i18n.FromContext(ctx).T("cmd.pdf2docx.processing", pdfFile))
 
-		err := cmd.Run()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "[失败] 转换失败: %s: %v\n", pdfFile, err)
-			continue
+		file := convertedFile{Input: pdfFile, Output: output, Status: statusConverted}
+		if _, err := runExternal(ctx, cmd); err != nil {
+			file.Status, file.Error = statusFailed, err.Error()
+			failed++
 		}
-		fmt.Printf("[成功] 转换完成: %s\n", pdfFile)
+		result.Files = append(result.Files, file)
 	}
 
-	return nil
+	if failed > 0 {
+		return result, i18n.Errorf("cmd.pdf2docx.failed", failed, len(pdfFiles))
+	}
+	return result, nil
 }
 
 func getFileName(fileName string) string {
@@ -140,7 +241,9 @@ func getFileName(fileName string) string {
 	return base + ".docx"
 }
 
-func readPdfFile(inputDir string) ([]string, error) {
+// readPdfFile returns the pdf files of inputDir, the errors are logged in the
+// language of p.
+func readPdfFile(p *i18n.Printer, inputDir string) ([]string, error) {
 	var pdfFiles []string
 	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -154,7 +257,7 @@ func readPdfFile(inputDir string) ([]string, error) {
 		return nil
 	})
 	if err != nil {
-		fmt.Println("Error walking input directory:", err)
+		log.Error(p.T("cmd.pdf2docx.walk_failed", err))
 		return nil, err
 	}
 	return pdfFiles, nil
@@ -0,0 +1,146 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CommandLines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "a.pdf")
+	assert.Nil(t, os.WriteFile(pdf, nil, 0o644))
+	urls := filepath.Join(dir, "urls.txt")
+	assert.Nil(t, os.WriteFile(urls, nil, 0o644))
+	videos := filepath.Join(dir, "videos")
+
+	tests := []struct {
+		name string
+		args []string
+		want [][]string
+	}{
+		{
+			name: "init",
+			args: []string{"init"},
+			want: [][]string{
+				{"pip", "install", "pdf2docx", "-i", "https://pypi.tuna.tsinghua.edu.cn/simple"},
+				{"pip", "install", "you-get", "-i", "https://pypi.tuna.tsinghua.edu.cn/simple"},
+			},
+		},
+		{
+			name: "download url",
+			args: []string{"download", "https://www.bilibili.com/video/BV1", "--output-dir", videos},
+			want: [][]string{{"you-get", "-o", videos, "https://www.bilibili.com/video/BV1"}},
+		},
+		{
+			name: "download file",
+			args: []string{"download", "--file", urls, "--output-dir", videos},
+			want: [][]string{{"you-get", "-o", videos, "-i", urls}},
+		},
+		{
+			name: "pdf2docx",
+			args: []string{"pdf2docx", pdf, "--output-dir", dir},
+			want: [][]string{{"pdf2docx", "convert", pdf, filepath.Join(dir, "a.docx")}},
+		},
+		{
+			name: "pdf2docx gui",
+			args: []string{"pdf2docx", "gui"},
+			want: [][]string{{"pdf2docx", "gui"}},
+		},
+		{
+			name: "pdf2docx deprecated --gui",
+			args: []string{"pdf2docx", "--gui"},
+			want: [][]string{{"pdf2docx", "gui"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &runner.Recorder{}
+			a := newTestApp()
+			a.Command().SetOut(&bytes.Buffer{})
+
+			assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(runner.NewContext(context.Background(), rec), tt.args))
+			assert.ElementsMatch(t, tt.want, rec.Lines())
+		})
+	}
+}
+
+func Test_CommandLinesFailure(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	rec := &runner.Recorder{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		if filepath.Base(cmd.Args[1]) == "a.pdf" {
+			return &runner.Result{ExitCode: 1}, errors.New("exit status 1")
+		}
+		return &runner.Result{}, nil
+	}}
+	a := newTestApp()
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+
+	assert.Equal(t, app.ExitCodeError, a.RunWithArgs(runner.NewContext(context.Background(), rec),
+		[]string{"pdf2docx", "--input-dir", dir, "--output-dir", dir}))
+	assert.Len(t, rec.Lines(), 2)
+	assert.Contains(t, stdout.String(), "exit status 1")
+}
+
+func Test_CommandMissingDependency(t *testing.T) {
+	rec := &runner.Recorder{Missing: []string{"you-get"}}
+	a := newTestApp()
+	a.Command().SetOut(&bytes.Buffer{})
+
+	assert.Equal(t, app.ExitCodeError, a.RunWithArgs(runner.NewContext(context.Background(), rec),
+		[]string{"download", "https://www.bilibili.com/video/BV1"}))
+	assert.Empty(t, rec.Lines())
+}
+
+func Test_CommandFlagGroups(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "a.pdf")
+	assert.Nil(t, os.WriteFile(pdf, nil, 0o644))
+	urls := filepath.Join(dir, "urls.txt")
+	assert.Nil(t, os.WriteFile(urls, nil, 0o644))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "download none", args: []string{"download"}, want: "one of --url or --file is required"},
+		{
+			name: "download url and file",
+			args: []string{"download", "--url", "https://www.bilibili.com/video/BV1", "--file", urls},
+			want: "--url and --file can not be used together",
+		},
+		{
+			name: "download args and file",
+			args: []string{"download", "https://www.bilibili.com/video/BV1", "--file", urls},
+			want: "--url and --file can not be used together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &runner.Recorder{}
+			a := newTestApp()
+			var stderr bytes.Buffer
+			a.Command().SetOut(&bytes.Buffer{})
+			a.Command().SetErr(&stderr)
+
+			assert.Equal(t, app.ExitCodeInvalidOptions, a.RunWithArgs(runner.NewContext(context.Background(), rec), tt.args))
+			assert.Contains(t, stderr.String(), tt.want)
+			assert.Empty(t, rec.Lines())
+		})
+	}
+}
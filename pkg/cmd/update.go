@@ -1,17 +1,139 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/i18n"
 	"github.com/lwm-galactic/utool/pkg/log"
+	"github.com/lwm-galactic/utool/pkg/selfupdate"
+	"github.com/lwm-galactic/utool/pkg/version"
 )
 
+type UpdateOptions struct {
+	BaseURL   string `mapstructure:"base-url" usage:"cmd.update.flag.base_url"`
+	PublicKey string `mapstructure:"public-key" usage:"cmd.update.flag.public_key"`
+	Check     bool   `mapstructure:"check" usage:"cmd.update.flag.check"`
+	Rollback  bool   `mapstructure:"rollback" usage:"cmd.update.flag.rollback"`
+	Force     bool   `mapstructure:"force" usage:"cmd.update.flag.force"`
+}
+
+func (o *UpdateOptions) Validate() []error {
+	var errs []error
+	if o.BaseURL == "" && !o.Rollback {
+		errs = append(errs, i18n.Errorf("cli.validate.required", "base-url"))
+	}
+	if o.Check && o.Rollback {
+		errs = append(errs, i18n.Errorf("cmd.update.check_rollback"))
+	}
+	if o.PublicKey != "" {
+		if _, err := selfupdate.ParsePublicKey(o.PublicKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func NewUpdateOptions() *UpdateOptions {
+	return &UpdateOptions{}
+}
+
 func NewUpdateCommand() *app.Command {
-	return app.NewCommand("update", "to update tool", app.WithCommandRunFunc(run))
+	return app.NewCommand("update", "cmd.update.short", app.WithCommandResultFunc(updateRun), app.WithCommandOptions(NewUpdateOptions()))
+}
+
+// checkResult is the result of update --check.
+type checkResult struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Newer   bool   `json:"newer"`
+}
+
+func (r checkResult) Text() string {
+	return r.Localize(nil)
+}
+
+// Localize returns the text of the result in the language of p.
+func (r checkResult) Localize(p *i18n.Printer) string {
+	if r.Newer {
+		return p.T("cmd.update.available", r.Latest, r.Current) + "\n"
+	}
+	return p.T("cmd.update.up_to_date", r.Current, r.Latest) + "\n"
+}
+
+// updateRun updates the executable, only --check has a result to print.
+func updateRun(ctx context.Context, option app.CliOptions) (interface{}, error) {
+	opts, ok := option.(*UpdateOptions)
+	if !ok {
+		return nil, fmt.Errorf("update: invalid options")
+	}
+
+	updater, err := newUpdater(opts)
+	if err != nil {
+		return nil, err
+	}
+	p := i18n.FromContext(ctx)
+
+	if opts.Rollback {
+		if app.IsDryRun(ctx) {
+			app.RecordAction(ctx, app.Action{Creates: []string{updater.Executable}})
+			return nil, nil
+		}
+		if err := updater.Rollback(); err != nil {
+			return nil, err
+		}
+		log.Info(p.T("cmd.update.rolled_back", updater.Executable))
+		return nil, nil
+	}
+
+	release, newer, err := updater.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Check {
+		return checkResult{Current: updater.Current, Latest: release.Version, Newer: newer}, nil
+	}
+	if !newer && !opts.Force {
+		log.Info(p.T("cmd.update.up_to_date", updater.Current, release.Version))
+		return nil, nil
+	}
+
+	if app.IsDryRun(ctx) {
+		app.RecordAction(ctx, app.Action{Creates: []string{updater.Executable}})
+		return nil, nil
+	}
+	log.Info(p.T("cmd.update.updating", updater.Executable, updater.Current, release.Version))
+	if err := updater.Apply(ctx, release); err != nil {
+		return nil, err
+	}
+	log.Info(p.T("cmd.update.done", updater.Current))
+	return nil, nil
 }
 
-func run(option app.CliOptions) error {
-	log.Info("update called")
+// newUpdater creates the updater of the running executable.
+func newUpdater(opts *UpdateOptions) (*selfupdate.Updater, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, err
+	}
 
-	log.Info("update called success")
-	return nil
+	info := version.Get()
+	updater := &selfupdate.Updater{
+		BaseURL:    opts.BaseURL,
+		Current:    info.GitVersion,
+		Platform:   info.Platform,
+		Executable: exe,
+	}
+	if opts.PublicKey != "" {
+		if updater.PublicKey, err = selfupdate.ParsePublicKey(opts.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+	return updater, nil
 }
@@ -0,0 +1,33 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/app"
+	"github.com/lwm-galactic/utool/pkg/selfupdate"
+	"github.com/lwm-galactic/utool/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UpdateCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(selfupdate.Manifest{
+			Version: "v99.0.0",
+			Assets:  map[string]selfupdate.Asset{runtime.GOOS + "/" + runtime.GOARCH: {URL: "tool"}},
+		})
+	}))
+	defer server.Close()
+
+	a := newTestApp()
+	var stdout bytes.Buffer
+	a.Command().SetOut(&stdout)
+
+	assert.Equal(t, app.ExitCodeOK, a.RunWithArgs(context.Background(), []string{"update", "--base-url", server.URL, "--check", "-o", "json"}))
+	assert.JSONEq(t, `{"current": "`+version.Get().GitVersion+`", "latest": "v99.0.0", "newer": true}`, stdout.String())
+}
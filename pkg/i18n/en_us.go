@@ -0,0 +1,194 @@
+package i18n
+
+// enUS is the en-US bundle, it is the Fallback bundle and must hold every key.
+var enUS = Bundle{
+	"i18n.unsupported": "unsupported language %q, must be one of %s",
+
+	// pkg/app
+	"app.error":                    "Error:",
+	"app.hint":                     "Hint:",
+	"app.hint.help":                "Run '%s --help' for usage.",
+	"app.args.none":                "%q does not take any arguments, got %q",
+	"app.args.too_few":             "%q requires at least %d argument(s) %s, got %d",
+	"app.args.too_many":            "%q accepts at most %d argument(s) %s, got %d",
+	"app.args.invalid":             "invalid argument %s %q: %v",
+	"app.options.invalid":          "invalid options:",
+	"app.flag.config":              "Read configuration from specified `FILE`, support JSON, TOML, YAML, HCL, or Java properties formats.",
+	"app.flag.dry_run":             "Print the external commands and the files the command would create instead of running it, exit with %d when there is something to do.",
+	"app.flag.help":                "Help for %s.",
+	"app.flag.help_command":        "Help for the %s command.",
+	"app.flag.lang":                "Language of the messages, one of %s, detected from LC_ALL, LC_MESSAGES or LANG by default.",
+	"app.flag.output":              "Output `FORMAT` of the command results, one of %s.",
+	"app.flag.print_config":        "Print the effective value and the source of every option of the command as `FORMAT` table or json, then exit.",
+	"app.flag.invalid_format":      "invalid --%s format %q",
+	"app.output.hint":              "Use --%s=%s.",
+	"app.print_config.hint":        "Use --print-config=table or --print-config=json.",
+	"app.print_config.unsupported": "%s does not support --%s, it has no options",
+	"app.print_config.header":      "NAME\tVALUE\tSOURCE",
+	"app.help.short":               "Help about any command.",
+	"app.help.long":                "Help provides help for any command in the application.\nSimply type %s help [path to command] for full details.",
+	"app.help.unknown_topic":       "Unknown help topic %#q",
+	"app.help.usage":               "Usage:",
+	"app.help.arguments":           "Arguments:",
+	"app.help.aliases":             "Aliases:",
+	"app.help.examples":            "Examples:",
+	"app.help.commands":            "Available Commands:",
+	"app.help.more":                "Use \"%s [command] --help\" for more information about a command.",
+	"app.help.deprecated":          "DEPRECATED: %s",
+	"app.help.experimental":        "EXPERIMENTAL: this command may change or be removed in a future release.",
+	"app.help.experimental_suffix": " (experimental)",
+	"app.deprecated.warning":       "Command %q is deprecated, %s",
+	"app.completion.short":         "Generate the autocompletion script for the specified shell",
+	"app.completion.long":          "Generate the autocompletion script for the specified shell, e.g. to load the\ncompletions in the current bash session:\n\n  source <(%s completion bash)",
+	"app.completion.unsupported":   "unsupported shell %q",
+	"app.version.short":            "Print the version information",
+	"app.list.short":               "List all available commands",
+	"app.list.flag.json":           "Print the command tree as JSON, same as --output=json.",
+	"app.list.title":               "Available commands:",
+	"app.list.more":                "To Use %s subcommands --help to show how subcommand use",
+	"app.list.plugin":              "plugin %s",
+	"app.list.aliases":             " (aliases: %s)",
+	"app.list.experimental":        " [experimental]",
+	"app.list.deprecated":          " [deprecated: %s]",
+	"app.list.header":              "COMMAND\tALIASES\tORIGIN\tDESCRIPTION",
+	"app.plugin.short":             "Manage the external plugins",
+	"app.plugin.list.short":        "List the %s-<name> plugins found on PATH and in ~/.%s/plugins",
+	"app.plugin.run_failed":        "failed to run plugin %s: %v",
+	"app.plugin.shadowed":          "shadowed by %s",
+	"app.plugin.shadowed_builtin":  "shadowed by the built-in command %s",
+	"app.plugin.list.header":       "NAME\tPATH\tWARNING",
+	"app.config.read":              "failed to read configuration file(%s): %v",
+	"app.config.not_mapping":       "configuration file %s is not a mapping",
+	"app.config.short":             "Manage the configuration file",
+	"app.config.view.short":        "Print the effective configuration merged from defaults, the configuration file and the environment",
+	"app.config.get.short":         "Print the effective value of a dotted configuration key, e.g. download.output-dir",
+	"app.config.get.not_set":       "configuration key %q is not set",
+	"app.config.set.short":         "Set a dotted configuration key in the configuration file, e.g. download.output-dir videos",
+	"app.config.set.done":          "%s set in %s",
+	"app.config.set.unknown":       "unknown configuration key %q",
+	"app.config.set.unknown_hint":  "Run '%s config view' to print the configuration keys.",
+	"app.config.init.short":        "Write a commented configuration file with the default values of the options",
+	"app.config.init.flag.force":   "Overwrite the configuration file if it exists.",
+	"app.config.init.exists":       "configuration file %s already exists",
+	"app.config.init.exists_hint":  "Use --force to overwrite it.",
+	"app.config.init.done":         "configuration file written to %s",
+	"app.config.init.comment":      "Configuration file of %s, generated by `%s config init`.\nOptions are resolved in the order: flag > env > command section > global section > defaults.",
+	"app.config.init.global":       "Options shared by all commands, e.g. %s, the language of the messages, one of %s.",
+	"app.config.path.short":        "Print the path of the configuration file in use",
+	"app.config.path.flag.all":     "Print the directories searched for the configuration file.",
+	"app.config.path.none":         "no configuration file found",
+	"app.config.path.none_hint":    "Run '%s config init' to create one.",
+	"app.dry_run.nothing":          "Nothing to do.",
+	"app.dry_run.run":              "Would run: %s",
+	"app.dry_run.dir":              "  dir:     %s",
+	"app.dry_run.env":              "  env:     %s",
+	"app.dry_run.inherited":        "inherited",
+	"app.dry_run.write":            "Would write:",
+	"app.dry_run.creates":          "  creates: %s",
+	"app.dry_run.header":           "COMMAND\tDIR\tENV\tCREATES",
+	"app.middleware.panicked":      "%s panicked: %v",
+	"app.middleware.failed_after":  "%s failed after %s",
+	"app.middleware.finished_in":   "%s finished in %s",
+	"app.middleware.missing":       "%s requires %s which is not installed",
+	"app.middleware.install_hint":  "Install %s and make sure it is on PATH.",
+	"app.middleware.init_hint":     "Run '%s' to install %s.",
+	"app.docs.short":               "Write the reference of every command as man pages, Markdown and a single HTML page",
+	"app.docs.flag.dir":            "Directory the docs are written to.",
+	"app.docs.flag.format":         "Formats of the docs, any of %s.",
+	"app.docs.argument":            "Argument",
+	"app.docs.flag":                "Flag",
+	"app.docs.description":         "Description",
+	"app.docs.env":                 "Environment",
+	"app.docs.config_key":          "Config key",
+	"app.docs.see_also":            "See also",
+	"app.log.starting":             "Starting %s ...",
+	"app.log.config_file":          "Config file used: `%s`",
+	"app.log.working_dir":          "WorkingDir: %s",
+	"app.log.config":               "Config: `%s`",
+
+	// pkg/cli
+	"cli.help.flags":              "%s flags:",
+	"cli.help.default":            " (default %s)",
+	"cli.help.deprecated":         " (DEPRECATED: %s)",
+	"cli.join.and":                "%s and %s",
+	"cli.join.or":                 "%s or %s",
+	"cli.join.separator":          ", ",
+	"cli.group.required":          "--%s is required",
+	"cli.group.required.help":     "--%s is required.",
+	"cli.group.exclusive":         "%s can not be used together",
+	"cli.group.exclusive.help":    "%s can not be used together.",
+	"cli.group.one_required":      "one of %s is required",
+	"cli.group.one_required.help": "One of %s is required.",
+	"cli.group.requires":          "--%s requires %s",
+	"cli.group.requires.help":     "--%s requires %s.",
+	"cli.validate.required":       "%s is required",
+	"cli.validate.enum":           "invalid %s %q, must be one of %s",
+	"cli.value.file_not_exist":    "file %s does not exist",
+	"cli.value.file_is_dir":       "%s is a directory, not a file",
+	"cli.value.file_extension":    "file %s must have a %s extension",
+	"cli.value.not_dir":           "%s is not a directory",
+	"cli.value.dir_not_exist":     "directory %s does not exist",
+	"cli.value.dir_not_creatable": "can not create directory %s, %s is not a directory",
+	"cli.value.enum":              "must be one of %s",
+	"cli.value.size":              "invalid size %q, use a number of bytes with an optional unit, e.g. 10MB or 1.5GiB",
+	"cli.value.rate":              "invalid rate %q, use a size per second, e.g. 2MB/s",
+	"cli.value.duration":          "invalid duration %q, use e.g. 30s, 5m or 1h30m",
+	"cli.value.duration_range":    "must be between %s and %s",
+	"cli.value.duration_min":      "must be at least %s",
+	"cli.value.url_scheme":        "must be an %s url",
+
+	// pkg/cmd
+	"cmd.description":                  "tool create by lwm",
+	"cmd.dependency_hint":              "%s is not installed, run `tool init` to install the dependencies.",
+	"cmd.download.short":               "Download videos from bilibili or youtube",
+	"cmd.download.long":                "Download videos from bilibili or youtube with you-get, from the addresses given as args or by --url, or listed one per line in the --file.",
+	"cmd.download.flag.url":            "Address of the video to download.",
+	"cmd.download.flag.file":           "File listing the addresses to download, one per line, to download in batch.",
+	"cmd.download.flag.output_dir":     "Directory the videos are downloaded to.",
+	"cmd.download.arg.url":             "addresses to download, several can be given",
+	"cmd.download.result.downloaded":   "[ok] downloaded: %s -> %s",
+	"cmd.download.result.failed":       "[failed] download failed: %s: %s",
+	"cmd.download.mkdir_failed":        "failed to create the output directory: %v",
+	"cmd.download.source_required":     "url or file is required",
+	"cmd.download.running":             "running: you-get %s",
+	"cmd.download.failed":              "you-get failed: %w",
+	"cmd.init.short":                   "Initialize the tool by installing its dependencies",
+	"cmd.init.long":                    "Initialize the tool by installing its dependencies, the python packages used by download and pdf2docx are installed with pip.",
+	"cmd.init.done":                    "init success",
+	"cmd.init.installed":               "all packages already installed",
+	"cmd.init.pip_failed":              "failed to install the python packages: %v",
+	"cmd.init.mkdir_failed":            "failed to create the work dir: %v",
+	"cmd.init.read_failed":             "failed to read %s: %v",
+	"cmd.init.parse_failed":            "failed to parse %s: %v",
+	"cmd.init.marshal_failed":          "failed to encode the installed packages: %v",
+	"cmd.init.write_failed":            "failed to write %s: %v",
+	"cmd.pdf2docx.short":               "Convert pdf files to docx",
+	"cmd.pdf2docx.convert.short":       "Convert pdf files to docx, same as pdf2docx",
+	"cmd.pdf2docx.gui.short":           "Open the pdf2docx graphical interface",
+	"cmd.pdf2docx.flag.input_dir":      "Directory of the pdf files to convert, every *.pdf file in it is converted to *.docx.",
+	"cmd.pdf2docx.flag.output_dir":     "Directory the docx files are written to.",
+	"cmd.pdf2docx.flag.file":           "A pdf file to convert to docx.",
+	"cmd.pdf2docx.flag.gui":            "Open the graphical interface instead of converting.",
+	"cmd.pdf2docx.flag.gui_deprecated": "use 'tool pdf2docx gui' instead",
+	"cmd.pdf2docx.arg.file":            "pdf files to convert, several can be given, only they are converted when set",
+	"cmd.pdf2docx.arg.extension":       "file must have a .pdf extension",
+	"cmd.pdf2docx.file_not_exist":      "file %s does not exist",
+	"cmd.pdf2docx.processing":          "Processing: %s",
+	"cmd.pdf2docx.result.converted":    "[ok] converted: %s -> %s",
+	"cmd.pdf2docx.result.failed":       "[failed] conversion failed: %s: %s",
+	"cmd.pdf2docx.result.header":       "INPUT\tOUTPUT\tSTATUS\tERROR",
+	"cmd.pdf2docx.failed":              "%d of %d files failed to convert",
+	"cmd.pdf2docx.walk_failed":         "failed to walk the input directory: %v",
+	"cmd.update.short":                 "Update the tool",
+	"cmd.update.flag.base_url":         "Address of the release server, the latest version is read from <base-url>/manifest.json.",
+	"cmd.update.flag.public_key":       "Base64 ed25519 public key verifying the signature of the releases, unsigned releases are rejected when set.",
+	"cmd.update.flag.check":            "Only check whether a newer version is available, without updating.",
+	"cmd.update.flag.rollback":         "Roll back to the version before the update.",
+	"cmd.update.flag.force":            "Reinstall the released version even if it is not newer.",
+	"cmd.update.check_rollback":        "check and rollback can not be used together",
+	"cmd.update.rolled_back":           "rolled back %s to the previous version",
+	"cmd.update.available":             "a newer version %s is available (current %s)",
+	"cmd.update.up_to_date":            "already up to date (current %s, latest %s)",
+	"cmd.update.updating":              "updating %s from %s to %s",
+	"cmd.update.done":                  "update success, run `tool update --rollback` to restore %s",
+}
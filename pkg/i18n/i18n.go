@@ -0,0 +1,307 @@
+// Package i18n translates the messages of the command line. A message is
+// looked up by its key, e.g. "cmd.download.short", in the bundle of the
+// language of a Printer, then in the bundle of the Fallback language. Text
+// which is not a key is returned as is, so that the apps built on pkg/app can
+// keep passing plain text. The functions of the package print in the language
+// selected by SetLanguage, the printers let every run of an app have its own
+// language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Languages shipped with a complete bundle.
+const (
+	EnUS = "en-US"
+	ZhCN = "zh-CN"
+
+	// Fallback is the language of the messages missing from the bundle of the
+	// selected language, and the language used when none is detected.
+	Fallback = EnUS
+)
+
+// Bundle maps the keys of the messages to their text in a language. The texts
+// are fmt formats when the message has arguments.
+type Bundle map[string]string
+
+var bundles = map[string]Bundle{
+	EnUS: enUS,
+	ZhCN: zhCN,
+}
+
+var (
+	mu       sync.RWMutex
+	language = Fallback
+)
+
+// Languages returns the supported languages, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(bundles))
+	for lang := range bundles {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Messages returns the bundle of lang, e.g. to check that it is complete.
+func Messages(lang string) (Bundle, bool) {
+	bundle, ok := bundles[lang]
+	return bundle, ok
+}
+
+// Match returns the supported language of tag, which is a language tag like
+// zh-CN or a POSIX locale like zh_CN.UTF-8. A tag matches the language with the
+// same primary subtag, e.g. zh-TW and zh match zh-CN, en-GB matches en-US.
+func Match(tag string) (string, bool) {
+	tag, _, _ = strings.Cut(tag, ".")
+	tag, _, _ = strings.Cut(tag, "@")
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if tag == "" {
+		return "", false
+	}
+
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, lang := range Languages() {
+		if strings.EqualFold(lang, tag) {
+			return lang, true
+		}
+	}
+	for _, lang := range Languages() {
+		if p, _, _ := strings.Cut(lang, "-"); strings.EqualFold(p, primary) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Detect returns the language of the first of tags which matches a supported
+// language, see Match, the Fallback language when none does. Tags are given
+// from the highest priority, e.g. the value of --lang, then of the
+// configuration and then SystemLocale.
+func Detect(tags ...string) string {
+	for _, tag := range tags {
+		if lang, ok := Match(tag); ok {
+			return lang
+		}
+	}
+	return Fallback
+}
+
+// SystemLocale returns the locale of the messages set by the environment, the
+// first one set of LC_ALL, LC_MESSAGES and LANG.
+func SystemLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// SetLanguage selects the language of the messages, tag is matched with Match.
+func SetLanguage(tag string) error {
+	lang, ok := Match(tag)
+	if !ok {
+		return Errorf("i18n.unsupported", tag, strings.Join(Languages(), ", "))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	language = lang
+	return nil
+}
+
+// Language returns the selected language.
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the message of key in the selected language, formatted with args
+// when there are any. Keys missing from the bundle are looked up in the
+// Fallback bundle, key itself is returned when it is in neither.
+func T(key string, args ...interface{}) string {
+	return (*Printer)(nil).T(key, args...)
+}
+
+// Errorf returns an error with the message of key formatted with args, the
+// message may wrap an error with %w.
+func Errorf(key string, args ...interface{}) error {
+	return (*Printer)(nil).Errorf(key, args...)
+}
+
+// Printer prints the messages in one language. The nil Printer prints in the
+// language selected by SetLanguage.
+type Printer struct {
+	lang string
+}
+
+// NewPrinter returns a printer of the language of tag, which is matched with
+// Match, of the Fallback language when it does not match.
+func NewPrinter(tag string) *Printer {
+	return &Printer{lang: Detect(tag)}
+}
+
+// Language returns the language of the printer.
+func (p *Printer) Language() string {
+	if p == nil {
+		return Language()
+	}
+	return p.lang
+}
+
+// T returns the message of key formatted with args, see T. The arguments
+// which are a Localizer are printed in the language of p.
+func (p *Printer) T(key string, args ...interface{}) string {
+	msg := lookup(p.Language(), key)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, p.localize(args)...)
+}
+
+// Errorf returns an error with the message of key formatted with args, the
+// message may wrap an error with %w. The message is printed in the language
+// of p, or in the one of another printer by Localize.
+func (p *Printer) Errorf(key string, args ...interface{}) error {
+	return &Error{Key: key, Args: args, printer: p}
+}
+
+// Localize returns err printed in the language of p when it is a Localizer,
+// e.g. an error of Errorf, err itself otherwise. The returned error wraps err.
+func (p *Printer) Localize(err error) error {
+	if _, ok := err.(Localizer); ok {
+		return &localizedError{err: err, printer: p}
+	}
+	return err
+}
+
+// localize returns args with the Localizer values printed in the language of
+// p, the errors are kept errors so that they can be wrapped with %w.
+func (p *Printer) localize(args []interface{}) []interface{} {
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case error:
+			localized[i] = p.Localize(arg)
+		case Localizer:
+			localized[i] = arg.Localize(p)
+		default:
+			localized[i] = arg
+		}
+	}
+	return localized
+}
+
+// Localizer is a value which can be printed in the language of a printer, e.g.
+// an error of Errorf or a List.
+type Localizer interface {
+	Localize(p *Printer) string
+}
+
+// Error is an error of Errorf, its message is formatted when it is printed.
+type Error struct {
+	// Key is the key of the message.
+	Key string
+	// Args format the message.
+	Args    []interface{}
+	printer *Printer
+}
+
+func (e *Error) Error() string {
+	return e.Localize(e.printer)
+}
+
+// Localize implements Localizer.
+func (e *Error) Localize(p *Printer) string {
+	return fmt.Errorf(lookup(p.Language(), e.Key), p.localize(e.Args)...).Error()
+}
+
+// Unwrap returns the errors wrapped with %w.
+func (e *Error) Unwrap() []error {
+	switch err := fmt.Errorf(lookup(Fallback, e.Key), e.Args...).(type) {
+	case interface{ Unwrap() error }:
+		return []error{err.Unwrap()}
+	case interface{ Unwrap() []error }:
+		return err.Unwrap()
+	}
+	return nil
+}
+
+// localizedError is an error printed in the language of printer.
+type localizedError struct {
+	err     error
+	printer *Printer
+}
+
+func (e *localizedError) Error() string {
+	return e.err.(Localizer).Localize(e.printer)
+}
+
+func (e *localizedError) Unwrap() error { return e.err }
+
+type printerKey struct{}
+
+// NewContext returns a copy of ctx carrying p, see FromContext.
+func NewContext(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// FromContext returns the printer carried by ctx, nil, which prints in the
+// language selected by SetLanguage, when there is none.
+func FromContext(ctx context.Context) *Printer {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(printerKey{}).(*Printer)
+	return p
+}
+
+// Conjunctions of the lists joined by Join, they are message keys.
+const (
+	And = "cli.join.and"
+	Or  = "cli.join.or"
+)
+
+// List is a list of words printed as a, b and c in the language of a printer.
+type List struct {
+	Words []string
+	// Conjunction is And or Or.
+	Conjunction string
+}
+
+// Join returns words as a List joined with conjunction, And or Or.
+func Join(words []string, conjunction string) List {
+	return List{Words: words, Conjunction: conjunction}
+}
+
+// Localize implements Localizer.
+func (l List) Localize(p *Printer) string {
+	if len(l.Words) < 2 {
+		return strings.Join(l.Words, "")
+	}
+	last := len(l.Words) - 1
+	return p.T(l.Conjunction, strings.Join(l.Words[:last], p.T("cli.join.separator")), l.Words[last])
+}
+
+// String prints the list in the language selected by SetLanguage.
+func (l List) String() string {
+	return l.Localize(nil)
+}
+
+func lookup(lang, key string) string {
+	if msg, ok := bundles[lang][key]; ok {
+		return msg
+	}
+	if msg, ok := bundles[Fallback][key]; ok {
+		return msg
+	}
+	return key
+}
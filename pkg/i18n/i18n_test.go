@@ -0,0 +1,208 @@
+package i18n_test
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+// sources are the packages whose messages are in the bundles.
+var sources = []string{"../app", "../cli", "../cli/flagtag", "../cmd", "../../cmd"}
+
+// keyPattern matches the string literals which are message keys.
+var keyPattern = regexp.MustCompile(`^(app|cli|cmd|i18n)(\.[a-z0-9_]+)+$`)
+
+var verbPattern = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// usedKeys returns the message keys used by the sources: the string literals
+// which look like keys and the usage and deprecated tags of the options.
+func usedKeys(t *testing.T) []string {
+	keys := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, dir := range sources {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		assert.NoError(t, err)
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(fset, file, nil, 0)
+			if !assert.NoError(t, err) {
+				continue
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ValueSpec:
+					// cobra annotations are named like keys but are not messages.
+					if len(n.Names) == 1 && strings.HasPrefix(n.Names[0].Name, "annotation") {
+						return false
+					}
+				case *ast.Field:
+					if n.Tag != nil {
+						tag, _ := strconv.Unquote(n.Tag.Value)
+						for _, name := range []string{"usage", "deprecated"} {
+							if key := reflect.StructTag(tag).Get(name); key != "" {
+								keys[key] = true
+							}
+						}
+					}
+				case *ast.BasicLit:
+					if n.Kind == token.STRING {
+						if s, err := strconv.Unquote(n.Value); err == nil && keyPattern.MatchString(s) {
+							keys[s] = true
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// verbs returns the verbs of format by argument index.
+func verbs(format string) map[int]string {
+	m := map[int]string{}
+	arg := 0
+	for _, match := range verbPattern.FindAllStringSubmatch(format, -1) {
+		if match[2] == "%" {
+			continue
+		}
+		if match[1] != "" {
+			arg, _ = strconv.Atoi(match[1])
+			arg--
+		}
+		m[arg] = match[2]
+		arg++
+	}
+	return m
+}
+
+func Test_UsedKeys(t *testing.T) {
+	keys := usedKeys(t)
+	assert.NotEmpty(t, keys)
+	for _, lang := range i18n.Languages() {
+		bundle, _ := i18n.Messages(lang)
+		for _, key := range keys {
+			assert.NotEmpty(t, bundle[key], "key %s is missing from the %s bundle", key, lang)
+		}
+	}
+}
+
+func Test_BundlesComplete(t *testing.T) {
+	fallback, ok := i18n.Messages(i18n.Fallback)
+	assert.True(t, ok)
+	for _, lang := range i18n.Languages() {
+		bundle, _ := i18n.Messages(lang)
+		assert.Len(t, bundle, len(fallback), "bundle %s", lang)
+		for key, msg := range fallback {
+			assert.NotEmpty(t, bundle[key], "key %s is missing from the %s bundle", key, lang)
+			assert.Equal(t, verbs(msg), verbs(bundle[key]), "verbs of %s in the %s bundle", key, lang)
+		}
+	}
+}
+
+func Test_Match(t *testing.T) {
+	tests := []struct {
+		tag  string
+		lang string
+		ok   bool
+	}{
+		{tag: "zh-CN", lang: i18n.ZhCN, ok: true},
+		{tag: "zh_CN.UTF-8", lang: i18n.ZhCN, ok: true},
+		{tag: "zh_TW", lang: i18n.ZhCN, ok: true},
+		{tag: "EN-us", lang: i18n.EnUS, ok: true},
+		{tag: "en_GB.UTF-8@euro", lang: i18n.EnUS, ok: true},
+		{tag: "C"},
+		{tag: "fr-FR"},
+		{tag: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			lang, ok := i18n.Match(tt.tag)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.lang, lang)
+		})
+	}
+
+	assert.Equal(t, i18n.ZhCN, i18n.Detect("", "C.UTF-8", "zh_CN.UTF-8", "en_US"))
+	assert.Equal(t, i18n.Fallback, i18n.Detect("POSIX"))
+}
+
+func Test_SystemLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "zh_CN.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, "zh_CN.UTF-8", i18n.SystemLocale())
+	os.Unsetenv("LC_MESSAGES")
+	assert.Equal(t, "en_US.UTF-8", i18n.SystemLocale())
+}
+
+func Test_T(t *testing.T) {
+	defer i18n.SetLanguage(i18n.Fallback)
+
+	assert.Error(t, i18n.SetLanguage("fr"))
+	assert.NoError(t, i18n.SetLanguage("zh_CN.UTF-8"))
+	assert.Equal(t, i18n.ZhCN, i18n.Language())
+	assert.Equal(t, "文件 a.txt 不存在", i18n.T("cli.value.file_not_exist", "a.txt"))
+	assert.Equal(t, "已在 b.yaml 中设置 a", i18n.T("app.config.set.done", "a", "b.yaml"))
+	// text which is not a key is returned as is.
+	assert.Equal(t, "tool create by lwm", i18n.T("tool create by lwm"))
+	assert.EqualError(t, i18n.Errorf("cmd.download.failed", os.ErrNotExist), "you-get 执行失败: file does not exist")
+	// messages missing from the bundle fall back to the Fallback bundle.
+	fallback, _ := i18n.Messages(i18n.Fallback)
+	fallback["test.only_english"] = "only %s"
+	defer delete(fallback, "test.only_english")
+	assert.Equal(t, "only english", i18n.T("test.only_english", "english"))
+
+	assert.NoError(t, i18n.SetLanguage("en"))
+	assert.Equal(t, "file a.txt does not exist", i18n.T("cli.value.file_not_exist", "a.txt"))
+}
+
+func Test_Printer(t *testing.T) {
+	p := i18n.NewPrinter("zh_CN.UTF-8")
+	assert.Equal(t, i18n.ZhCN, p.Language())
+	assert.Equal(t, i18n.EnUS, i18n.NewPrinter("fr").Language())
+	// the nil printer prints in the selected language.
+	var global *i18n.Printer
+	assert.Equal(t, i18n.Language(), global.Language())
+
+	assert.Equal(t, "文件 a.txt 不存在", p.T("cli.value.file_not_exist", "a.txt"))
+	assert.Equal(t, "file a.txt does not exist", i18n.T("cli.value.file_not_exist", "a.txt"))
+	list := i18n.Join([]string{".pdf", ".doc", ".txt"}, i18n.Or)
+	assert.Equal(t, "文件 a 的扩展名必须是 .pdf、.doc 或 .txt", p.T("cli.value.file_extension", "a", list))
+	assert.Equal(t, ".pdf, .doc or .txt", list.String())
+
+	// errors are printed in the language of the printer they are localized by.
+	err := i18n.Errorf("cmd.download.failed", os.ErrNotExist)
+	assert.EqualError(t, err, "you-get failed: file does not exist")
+	assert.EqualError(t, p.Localize(err), "you-get 执行失败: file does not exist")
+	assert.ErrorIs(t, p.Localize(err), os.ErrNotExist)
+	assert.EqualError(t, p.Errorf("cmd.download.failed", err), "you-get 执行失败: you-get 执行失败: file does not exist")
+	plain := errors.New("plain")
+	assert.Equal(t, plain, p.Localize(plain))
+	assert.Nil(t, p.Localize(nil))
+
+	ctx := i18n.NewContext(context.Background(), p)
+	assert.Equal(t, p, i18n.FromContext(ctx))
+	assert.Nil(t, i18n.FromContext(context.Background()))
+}
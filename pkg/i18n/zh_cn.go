@@ -0,0 +1,194 @@
+package i18n
+
+// zhCN is the zh-CN bundle.
+var zhCN = Bundle{
+	"i18n.unsupported": "不支持的语言 %q, 可选值为 %s",
+
+	// pkg/app
+	"app.error":                    "错误:",
+	"app.hint":                     "提示:",
+	"app.hint.help":                "运行 '%s --help' 查看用法。",
+	"app.args.none":                "%q 不接受参数, 但指定了 %q",
+	"app.args.too_few":             "%q 至少需要 %d 个参数 %s, 但只指定了 %d 个",
+	"app.args.too_many":            "%q 最多接受 %d 个参数 %s, 但指定了 %d 个",
+	"app.args.invalid":             "无效的参数 %s %q: %v",
+	"app.options.invalid":          "无效的选项:",
+	"app.flag.config":              "从指定的配置文件 `FILE` 读取配置, 支持 JSON, TOML, YAML, HCL 和 Java properties 格式。",
+	"app.flag.dry_run":             "只打印命令将要执行的外部命令和将要创建的文件, 不实际执行, 有待执行的操作时以 %d 退出。",
+	"app.flag.help":                "%s 的帮助信息。",
+	"app.flag.help_command":        "%s 命令的帮助信息。",
+	"app.flag.lang":                "消息的语言, 可选值为 %s, 默认从 LC_ALL, LC_MESSAGES 或 LANG 检测。",
+	"app.flag.output":              "命令结果的输出格式 `FORMAT`, 可选值为 %s。",
+	"app.flag.print_config":        "以 `FORMAT` table 或 json 格式打印命令每个选项的生效值和来源, 然后退出。",
+	"app.flag.invalid_format":      "无效的 --%s 格式 %q",
+	"app.output.hint":              "使用 --%s=%s。",
+	"app.print_config.hint":        "使用 --print-config=table 或 --print-config=json。",
+	"app.print_config.unsupported": "%s 不支持 --%s, 它没有选项",
+	"app.print_config.header":      "名称\t值\t来源",
+	"app.help.short":               "查看任意命令的帮助信息。",
+	"app.help.long":                "help 可以查看应用中任意命令的帮助信息。\n输入 %s help [命令路径] 查看完整说明。",
+	"app.help.unknown_topic":       "未知的帮助主题 %#q",
+	"app.help.usage":               "用法:",
+	"app.help.arguments":           "参数:",
+	"app.help.aliases":             "别名:",
+	"app.help.examples":            "示例:",
+	"app.help.commands":            "可用命令:",
+	"app.help.more":                "使用 \"%s [command] --help\" 查看命令的更多信息。",
+	"app.help.deprecated":          "已弃用: %s",
+	"app.help.experimental":        "实验性: 该命令可能在以后的版本中修改或删除。",
+	"app.help.experimental_suffix": " (实验性)",
+	"app.deprecated.warning":       "命令 %q 已弃用, %s",
+	"app.completion.short":         "为指定的 shell 生成自动补全脚本",
+	"app.completion.long":          "为指定的 shell 生成自动补全脚本, 例如在当前 bash 会话中加载补全:\n\n  source <(%s completion bash)",
+	"app.completion.unsupported":   "不支持的 shell %q",
+	"app.version.short":            "打印版本信息",
+	"app.list.short":               "列出所有可用的命令",
+	"app.list.flag.json":           "以 JSON 格式打印命令树, 同 --output=json。",
+	"app.list.title":               "可用命令:",
+	"app.list.more":                "使用 %s 子命令 --help 查看子命令的用法",
+	"app.list.plugin":              "插件 %s",
+	"app.list.aliases":             " (别名: %s)",
+	"app.list.experimental":        " [实验性]",
+	"app.list.deprecated":          " [已弃用: %s]",
+	"app.list.header":              "命令\t别名\t来源\t描述",
+	"app.plugin.short":             "管理外部插件",
+	"app.plugin.list.short":        "列出 PATH 和 ~/.%[2]s/plugins 中的 %[1]s-<name> 插件",
+	"app.plugin.run_failed":        "运行插件 %s 失败: %v",
+	"app.plugin.shadowed":          "被 %s 覆盖",
+	"app.plugin.shadowed_builtin":  "被内置命令 %s 覆盖",
+	"app.plugin.list.header":       "名称\t路径\t警告",
+	"app.config.read":              "读取配置文件(%s)失败: %v",
+	"app.config.not_mapping":       "配置文件 %s 不是一个映射",
+	"app.config.short":             "管理配置文件",
+	"app.config.view.short":        "打印由默认值, 配置文件和环境变量合并后的生效配置",
+	"app.config.get.short":         "打印以点分隔的配置项的生效值, 例如 download.output-dir",
+	"app.config.get.not_set":       "配置项 %q 未设置",
+	"app.config.set.short":         "在配置文件中设置以点分隔的配置项, 例如 download.output-dir videos",
+	"app.config.set.done":          "已在 %[2]s 中设置 %[1]s",
+	"app.config.set.unknown":       "未知的配置项 %q",
+	"app.config.set.unknown_hint":  "运行 '%s config view' 查看配置项。",
+	"app.config.init.short":        "生成带注释的配置文件, 包含选项的默认值",
+	"app.config.init.flag.force":   "配置文件已存在时覆盖它。",
+	"app.config.init.exists":       "配置文件 %s 已存在",
+	"app.config.init.exists_hint":  "使用 --force 覆盖它。",
+	"app.config.init.done":         "配置文件已写入 %s",
+	"app.config.init.comment":      "%s 的配置文件, 由 `%s config init` 生成。\n选项按以下顺序生效: 命令行参数 > 环境变量 > 命令配置段 > 全局配置段 > 默认值。",
+	"app.config.init.global":       "所有命令共享的选项, 例如消息的语言 %s, 可选值为 %s。",
+	"app.config.path.short":        "打印正在使用的配置文件路径",
+	"app.config.path.flag.all":     "打印查找配置文件的所有目录。",
+	"app.config.path.none":         "没有找到配置文件",
+	"app.config.path.none_hint":    "运行 '%s config init' 创建一个。",
+	"app.dry_run.nothing":          "没有要执行的操作。",
+	"app.dry_run.run":              "将执行: %s",
+	"app.dry_run.dir":              "  目录:     %s",
+	"app.dry_run.env":              "  环境变量: %s",
+	"app.dry_run.inherited":        "继承",
+	"app.dry_run.write":            "将写入:",
+	"app.dry_run.creates":          "  创建:     %s",
+	"app.dry_run.header":           "命令\t目录\t环境变量\t创建",
+	"app.middleware.panicked":      "%s 发生 panic: %v",
+	"app.middleware.failed_after":  "%s 在 %s 后失败",
+	"app.middleware.finished_in":   "%s 用时 %s 完成",
+	"app.middleware.missing":       "%s 依赖的 %s 没有安装",
+	"app.middleware.install_hint":  "安装 %s 并确保它在 PATH 中。",
+	"app.middleware.init_hint":     "运行 '%s' 安装 %s。",
+	"app.docs.short":               "将每个命令的参考文档写成 man 手册, Markdown 和单页 HTML",
+	"app.docs.flag.dir":            "文档输出的文件夹。",
+	"app.docs.flag.format":         "文档的格式, 可以是 %s 中的多个。",
+	"app.docs.argument":            "参数",
+	"app.docs.flag":                "选项",
+	"app.docs.description":         "说明",
+	"app.docs.env":                 "环境变量",
+	"app.docs.config_key":          "配置项",
+	"app.docs.see_also":            "另请参阅",
+	"app.log.starting":             "正在启动 %s ...",
+	"app.log.config_file":          "使用的配置文件: `%s`",
+	"app.log.working_dir":          "工作目录: %s",
+	"app.log.config":               "配置: `%s`",
+
+	// pkg/cli
+	"cli.help.flags":              "%s 选项:",
+	"cli.help.default":            " (默认值 %s)",
+	"cli.help.deprecated":         " (已弃用: %s)",
+	"cli.join.and":                "%s 和 %s",
+	"cli.join.or":                 "%s 或 %s",
+	"cli.join.separator":          "、",
+	"cli.group.required":          "必须指定 --%s",
+	"cli.group.required.help":     "必须指定 --%s。",
+	"cli.group.exclusive":         "%s 不能同时使用",
+	"cli.group.exclusive.help":    "%s 不能同时使用。",
+	"cli.group.one_required":      "必须指定 %s 中的一个",
+	"cli.group.one_required.help": "必须指定 %s 中的一个。",
+	"cli.group.requires":          "--%s 需要同时指定 %s",
+	"cli.group.requires.help":     "--%s 需要同时指定 %s。",
+	"cli.validate.required":       "必须指定 %s",
+	"cli.validate.enum":           "无效的 %s %q, 可选值为 %s",
+	"cli.value.file_not_exist":    "文件 %s 不存在",
+	"cli.value.file_is_dir":       "%s 是一个文件夹, 不是文件",
+	"cli.value.file_extension":    "文件 %s 的扩展名必须是 %s",
+	"cli.value.not_dir":           "%s 不是一个文件夹",
+	"cli.value.dir_not_exist":     "文件夹 %s 不存在",
+	"cli.value.dir_not_creatable": "无法创建文件夹 %s, %s 不是一个文件夹",
+	"cli.value.enum":              "可选值为 %s",
+	"cli.value.size":              "无效的大小 %q, 使用字节数加可选的单位, 例如 10MB 或 1.5GiB",
+	"cli.value.rate":              "无效的速率 %q, 使用每秒的大小, 例如 2MB/s",
+	"cli.value.duration":          "无效的时长 %q, 例如 30s, 5m 或 1h30m",
+	"cli.value.duration_range":    "必须在 %s 和 %s 之间",
+	"cli.value.duration_min":      "不能小于 %s",
+	"cli.value.url_scheme":        "必须是 %s 地址",
+
+	// pkg/cmd
+	"cmd.description":                  "lwm 开发的工具集",
+	"cmd.dependency_hint":              "%s 没有安装, 运行 `tool init` 安装依赖。",
+	"cmd.download.short":               "用于下载 bilibili | youtube 的视频工具",
+	"cmd.download.long":                "用于下载 bilibili | youtube 的视频工具, 通过 you-get 下载参数和 --url 指定的地址或 --file 中每行一个的地址",
+	"cmd.download.flag.url":            "指定一个下载地址",
+	"cmd.download.flag.file":           "指定一个文件,一行是一个下载地址 用于批量下载",
+	"cmd.download.flag.output_dir":     "设置下载输出文件夹",
+	"cmd.download.arg.url":             "下载地址, 可以指定多个",
+	"cmd.download.result.downloaded":   "[成功] 下载完成: %s -> %s",
+	"cmd.download.result.failed":       "[失败] 下载失败: %s: %s",
+	"cmd.download.mkdir_failed":        "创建输出目录失败: %v",
+	"cmd.download.source_required":     "必须指定 url 或 file 参数",
+	"cmd.download.running":             "执行命令: you-get %s",
+	"cmd.download.failed":              "you-get 执行失败: %w",
+	"cmd.init.short":                   "初始化工具, 安装依赖",
+	"cmd.init.long":                    "初始化工具, 使用 pip 安装 download 和 pdf2docx 依赖的 python 包",
+	"cmd.init.done":                    "初始化成功",
+	"cmd.init.installed":               "所有依赖已经安装",
+	"cmd.init.pip_failed":              "安装 python 包失败: %v",
+	"cmd.init.mkdir_failed":            "创建工作目录失败: %v",
+	"cmd.init.read_failed":             "读取 %s 失败: %v",
+	"cmd.init.parse_failed":            "解析 %s 失败: %v",
+	"cmd.init.marshal_failed":          "JSON 序列化失败: %v",
+	"cmd.init.write_failed":            "写入 %s 失败: %v",
+	"cmd.pdf2docx.short":               "将 pdf 文件转换成 docx",
+	"cmd.pdf2docx.convert.short":       "将 pdf 文件转换成 docx, 同 pdf2docx",
+	"cmd.pdf2docx.gui.short":           "打开 pdf2docx 图形化界面",
+	"cmd.pdf2docx.flag.input_dir":      "设置 pdf2docx 要转换 pdf文件 所在的文件夹 (批量操作将该文件夹下的所有 *.pdf 文件转换成 *.docx)",
+	"cmd.pdf2docx.flag.output_dir":     "设置 pdf2docx 转换 docx文件后 所在的文件夹",
+	"cmd.pdf2docx.flag.file":           "指定一个pdf文件转换成docx",
+	"cmd.pdf2docx.flag.gui":            "是否使用图形化界面操作 true or false",
+	"cmd.pdf2docx.flag.gui_deprecated": "请使用 'tool pdf2docx gui' 代替",
+	"cmd.pdf2docx.arg.file":            "要转换的 pdf 文件, 可以指定多个, 指定后只转换这些文件",
+	"cmd.pdf2docx.arg.extension":       "文件的扩展名必须是 .pdf",
+	"cmd.pdf2docx.file_not_exist":      "指定的文件不存在: %s",
+	"cmd.pdf2docx.processing":          "正在处理: %s",
+	"cmd.pdf2docx.result.converted":    "[成功] 转换完成: %s -> %s",
+	"cmd.pdf2docx.result.failed":       "[失败] 转换失败: %s: %s",
+	"cmd.pdf2docx.result.header":       "输入\t输出\t状态\t错误",
+	"cmd.pdf2docx.failed":              "%[2]d 个文件中有 %[1]d 个转换失败",
+	"cmd.pdf2docx.walk_failed":         "遍历输入文件夹失败: %v",
+	"cmd.update.short":                 "更新工具",
+	"cmd.update.flag.base_url":         "发布服务器地址, 从 <base-url>/manifest.json 读取最新版本",
+	"cmd.update.flag.public_key":       "用于校验发布签名的 base64 ed25519 公钥, 设置后拒绝未签名的版本",
+	"cmd.update.flag.check":            "只检查是否有新版本, 不更新",
+	"cmd.update.flag.rollback":         "回滚到更新前的版本",
+	"cmd.update.flag.force":            "即使没有新版本也重新安装发布的版本",
+	"cmd.update.check_rollback":        "check 和 rollback 不能同时使用",
+	"cmd.update.rolled_back":           "已将 %s 回滚到之前的版本",
+	"cmd.update.available":             "有新版本 %s 可用 (当前版本 %s)",
+	"cmd.update.up_to_date":            "已经是最新版本 (当前版本 %s, 最新版本 %s)",
+	"cmd.update.updating":              "正在将 %s 从 %s 更新到 %s",
+	"cmd.update.done":                  "更新成功, 运行 `tool update --rollback` 可以恢复到 %s",
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"strings"
 	"sync"
 )
 
@@ -183,6 +184,25 @@ func Init(opts *Options) {
 	std = New(opts)
 }
 
+// RedirectOutput writes the logs of the global logger to paths, e.g. stderr,
+// with its other options unchanged until restore is called.
+func RedirectOutput(paths ...string) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	old := std
+	opts := old.opts
+	opts.OutputPaths = paths
+	std = New(&opts)
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		std.Flush()
+		std = old
+		zap.RedirectStdLog(old.infoLogger.log)
+	}
+}
+
 // New create logger by opts which can custmoized by command arguments.
 func New(opts *Options) *zapLogger {
 	if opts == nil {
@@ -193,9 +213,10 @@ func New(opts *Options) *zapLogger {
 	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
 		zapLevel = zapcore.InfoLevel
 	}
+	format := strings.ToLower(opts.Format)
 	encodeLevel := zapcore.CapitalLevelEncoder
 	// when output to local path, with color is forbidden
-	if opts.Format == consoleFormat {
+	if format == consoleFormat {
 		encodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
@@ -222,7 +243,7 @@ func New(opts *Options) *zapLogger {
 			Initial:    100,
 			Thereafter: 100,
 		},
-		Encoding:         opts.Format,
+		Encoding:         format,
 		EncoderConfig:    encoderConfig,
 		OutputPaths:      opts.OutputPaths,
 		ErrorOutputPaths: opts.ErrorOutputPaths,
@@ -234,6 +255,7 @@ func New(opts *Options) *zapLogger {
 		panic(err)
 	}
 	logger := &zapLogger{
+		opts:      *opts,
 		zapLogger: l.Named(opts.Name),
 		infoLogger: infoLogger{
 			log:   l,
@@ -252,6 +274,8 @@ type zapLogger struct {
 	// deals with our desire to have multiple verbosity levels.
 	zapLogger *zap.Logger
 	infoLogger
+	// opts 创建日志器的配置项, 用于 RedirectOutput.
+	opts Options
 }
 
 // V return a leveled InfoLogger.
@@ -1,7 +1,10 @@
 package log_test
 
 import (
+	"fmt"
 	"github.com/lwm-galactic/utool/pkg/log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/pflag"
@@ -41,3 +44,44 @@ func Test_Option(t *testing.T) {
 
 	assert.Equal(t, "debug", opt.Level)
 }
+
+func Test_OptionFormat(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opt := log.NewOptions()
+	opt.AddFlags(fs)
+
+	assert.Nil(t, fs.Parse([]string{"--log.format=json"}))
+	assert.Equal(t, "json", opt.Format)
+	assert.Empty(t, opt.Validate())
+
+	// the format is case insensitive.
+	assert.Nil(t, fs.Parse([]string{"--log.format=JSON"}))
+	assert.Empty(t, opt.Validate())
+
+	assert.Nil(t, fs.Parse([]string{"--log.format=xml"}))
+	assert.Equal(t, []error{fmt.Errorf(`not a valid log format: "xml"`)}, opt.Validate())
+}
+
+func Test_RedirectOutput(t *testing.T) {
+	dir := t.TempDir()
+	file, redirected := filepath.Join(dir, "app.log"), filepath.Join(dir, "redirected.log")
+	opts := log.NewOptions()
+	opts.Format = "json"
+	opts.OutputPaths = []string{file}
+	log.Init(opts)
+	defer log.Init(log.NewOptions())
+
+	restore := log.RedirectOutput(redirected)
+	log.Info("redirected")
+	restore()
+	log.Info("restored")
+	log.Flush()
+
+	data, err := os.ReadFile(redirected)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"message":"redirected"`)
+	data, err = os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"message":"restored"`)
+	assert.NotContains(t, string(data), "redirected")
+}
@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/log"
+)
+
+// KillGracePeriod is how long a canceled command is given to exit after it has
+// been asked to terminate before it is killed.
+const KillGracePeriod = 5 * time.Second
+
+// ExecRunner runs the commands as child processes. When the context of a
+// command is done the process and its children are asked to terminate, and
+// killed if they are still running after KillGracePeriod.
+type ExecRunner struct {
+	// Stdout and Stderr receive the Inherit output, os.Stdout and os.Stderr when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// New creates an ExecRunner writing the Inherit output to os.Stdout and os.Stderr.
+func New() *ExecRunner {
+	return &ExecRunner{}
+}
+
+// Run implements Runner.
+func (r *ExecRunner) Run(ctx context.Context, c Command) (*Result, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	switch c.Output {
+	case Capture:
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	case Log:
+		outLog := &lineWriter{logf: log.Infof, prefix: c.Name}
+		errLog := &lineWriter{logf: log.Warnf, prefix: c.Name}
+		defer outLog.Flush()
+		defer errLog.Flush()
+		cmd.Stdout, cmd.Stderr = outLog, errLog
+	default:
+		cmd.Stdout, cmd.Stderr = r.stdout(), r.stderr()
+	}
+
+	// stopKill is set by Cancel, which returns before Run does.
+	var stopKill func() bool
+	setProcessGroup(cmd)
+	cmd.Cancel = func() (err error) {
+		stopKill, err = terminateProcessGroup(cmd)
+		return err
+	}
+	cmd.WaitDelay = KillGracePeriod
+
+	start := time.Now()
+	err := cmd.Run()
+	if stopKill != nil {
+		stopKill()
+	}
+	result := &Result{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && c.Timeout > 0 {
+		err = fmt.Errorf("%s timed out after %s: %w", c.Name, c.Timeout, context.DeadlineExceeded)
+	}
+	return result, err
+}
+
+// LookPath implements Runner.
+func (r *ExecRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+func (r *ExecRunner) stdout() io.Writer {
+	if r.Stdout != nil {
+		return r.Stdout
+	}
+	return os.Stdout
+}
+
+func (r *ExecRunner) stderr() io.Writer {
+	if r.Stderr != nil {
+		return r.Stderr
+	}
+	return os.Stderr
+}
+
+// lineWriter logs every line written to it.
+type lineWriter struct {
+	mu     sync.Mutex
+	logf   func(format string, args ...interface{})
+	prefix string
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs the last line if it does not end with a newline.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) > 0 {
+		w.logf("[%s] %s", w.prefix, line)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts the command in its own process group so that the
+// whole process tree can be signaled.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessGroup sends SIGTERM to the process group of cmd and SIGKILL
+// once KillGracePeriod has elapsed. stop cancels the SIGKILL, it must be called
+// once the command has exited since the group id may then be reused.
+func terminateProcessGroup(cmd *exec.Cmd) (stop func() bool, err error) {
+	pgid := -cmd.Process.Pid
+	kill := time.AfterFunc(KillGracePeriod, func() {
+		_ = syscall.Kill(pgid, syscall.SIGKILL)
+	})
+
+	return kill.Stop, syscall.Kill(pgid, syscall.SIGTERM)
+}
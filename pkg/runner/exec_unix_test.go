@@ -0,0 +1,56 @@
+//go:build !windows
+
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/lwm-galactic/utool/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExecRunnerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	// the shell spawns a child which must be terminated with it.
+	start := time.Now()
+	_, err := runner.New().Run(ctx, runner.Command{Name: "sh", Args: []string{"-c", "sleep 30 & wait"}})
+
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), runner.KillGracePeriod)
+}
+
+func Test_ExecRunnerTimeout(t *testing.T) {
+	_, err := runner.New().Run(context.Background(), runner.Command{Name: "sleep", Args: []string{"30"}, Timeout: 100 * time.Millisecond})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.EqualError(t, err, "sleep timed out after 100ms: context deadline exceeded")
+}
+
+func Test_ExecRunnerCapture(t *testing.T) {
+	dir := t.TempDir()
+	result, err := runner.New().Run(context.Background(), runner.Command{
+		Name:   "sh",
+		Args:   []string{"-c", `pwd; echo "$GREETING"; echo oops >&2; exit 3`},
+		Dir:    dir,
+		Env:    []string{"GREETING=hello world"},
+		Output: runner.Capture,
+	})
+
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, dir+"\nhello world\n", string(result.Stdout))
+	assert.Equal(t, "oops\n", string(result.Stderr))
+}
+
+func Test_ExecRunnerNotFound(t *testing.T) {
+	_, err := runner.New().Run(context.Background(), runner.Command{Name: "utool-test-missing-command"})
+
+	assert.True(t, errors.Is(err, exec.ErrNotFound))
+}
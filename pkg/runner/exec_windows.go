@@ -0,0 +1,16 @@
+//go:build windows
+
+package runner
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on windows.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the process, windows has no graceful signal to
+// send to a console process tree.
+func terminateProcessGroup(cmd *exec.Cmd) (stop func() bool, err error) {
+	return func() bool { return false }, cmd.Process.Kill()
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+)
+
+// Recorder is a fake Runner recording the commands instead of running them,
+// e.g. to test the command lines built by a command without its tools installed.
+type Recorder struct {
+	mu       sync.Mutex
+	commands []Command
+
+	// Handler returns the outcome of cmd, the commands succeed with an empty
+	// result when nil.
+	Handler func(cmd Command) (*Result, error)
+	// Missing are the executables LookPath does not find, every other one is found.
+	Missing []string
+}
+
+// Run implements Runner.
+func (r *Recorder) Run(ctx context.Context, cmd Command) (*Result, error) {
+	r.mu.Lock()
+	r.commands = append(r.commands, cmd)
+	r.mu.Unlock()
+
+	if r.Handler != nil {
+		return r.Handler(cmd)
+	}
+	return &Result{}, nil
+}
+
+// LookPath implements Runner.
+func (r *Recorder) LookPath(name string) (string, error) {
+	for _, missing := range r.Missing {
+		if missing == name {
+			return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+	}
+	return name, nil
+}
+
+// Commands returns the commands run so far.
+func (r *Recorder) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Command(nil), r.commands...)
+}
+
+// Lines returns the command lines run so far.
+func (r *Recorder) Lines() [][]string {
+	var lines [][]string
+	for _, cmd := range r.Commands() {
+		lines = append(lines, cmd.Line())
+	}
+	return lines
+}
@@ -0,0 +1,107 @@
+// Package runner runs the external tools used by the commands, e.g. pip,
+// you-get and pdf2docx.
+//
+// Commands get the Runner from their context with FromContext so that tests
+// can replace it with a Recorder, and dry runs and audit logging wrap it with
+// DryRun and Audit.
+package runner
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output selects where the output of a command goes.
+type Output int
+
+const (
+	// Inherit streams the output to the stdout and stderr of the runner.
+	Inherit Output = iota
+	// Log streams every line of the output to pkg/log.
+	Log
+	// Capture returns the output in the Result.
+	Capture
+)
+
+// Command is an external command to run.
+type Command struct {
+	// Name is the name or the path of the executable.
+	Name string
+	Args []string
+	// Dir is the working directory, the one of the process when empty.
+	Dir string
+	// Env are KEY=VALUE variables set in addition to the environment of the
+	// process, which is inherited.
+	Env []string
+	// Timeout kills the command when it is still running after it, no timeout
+	// when zero.
+	Timeout time.Duration
+	// Output selects where the output of the command goes.
+	Output Output
+	// Creates are the files the command creates, they are reported by dry runs.
+	Creates []string
+}
+
+// Line returns the command line of c.
+func (c Command) Line() []string {
+	return append([]string{c.Name}, c.Args...)
+}
+
+// String returns the command line of c as typed in a shell.
+func (c Command) String() string {
+	return ShellJoin(c.Line())
+}
+
+// Result is the outcome of a command.
+type Result struct {
+	// ExitCode is the exit code of the command, -1 if it did not exit normally.
+	ExitCode int
+	// Stdout and Stderr hold the output of the command with the Capture output.
+	Stdout []byte
+	Stderr []byte
+	// Duration is how long the command ran.
+	Duration time.Duration
+}
+
+// Runner runs external commands.
+type Runner interface {
+	// Run runs cmd and waits for it, the result is returned along with the
+	// error when the command fails.
+	Run(ctx context.Context, cmd Command) (*Result, error)
+	// LookPath returns the path of the executable name.
+	LookPath(name string) (string, error)
+}
+
+// Default is the runner used when the context has none, it runs the commands
+// with an audit log.
+var Default Runner = Audit(New())
+
+type runnerContextKey struct{}
+
+// NewContext returns a copy of ctx in which r is the runner.
+func NewContext(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, runnerContextKey{}, r)
+}
+
+// FromContext returns the runner of ctx, Default when it has none.
+func FromContext(ctx context.Context) Runner {
+	if r, ok := ctx.Value(runnerContextKey{}).(Runner); ok {
+		return r
+	}
+	return Default
+}
+
+// ShellJoin joins args into a command line, args which the shell would split
+// or expand are quoted.
+func ShellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/lwm-galactic/utool/pkg/log"
+)
+
+// wrapped is a Runner replacing the Run method of the Runner it embeds.
+type wrapped struct {
+	Runner
+	run func(ctx context.Context, cmd Command) (*Result, error)
+}
+
+func (w wrapped) Run(ctx context.Context, cmd Command) (*Result, error) {
+	return w.run(ctx, cmd)
+}
+
+// Audit returns a runner logging every command run by next with its working
+// dir, exit code and duration.
+func Audit(next Runner) Runner {
+	return wrapped{Runner: next, run: func(ctx context.Context, cmd Command) (*Result, error) {
+		if cmd.Dir != "" {
+			log.Infof("run: %s in %s", cmd, cmd.Dir)
+		} else {
+			log.Infof("run: %s", cmd)
+		}
+		result, err := next.Run(ctx, cmd)
+		switch {
+		case err != nil && result != nil:
+			log.Warnf("run: %s failed with exit code %d after %s: %v", cmd.Name, result.ExitCode, result.Duration, err)
+		case err != nil:
+			log.Warnf("run: %s failed: %v", cmd.Name, err)
+		default:
+			log.Infof("run: %s exited with code %d after %s", cmd.Name, result.ExitCode, result.Duration)
+		}
+		return result, err
+	}}
+}
+
+// DryRun returns a runner passing the commands to record instead of running
+// them, the executables are still looked up by next.
+func DryRun(next Runner, record func(cmd Command)) Runner {
+	return wrapped{Runner: next, run: func(ctx context.Context, cmd Command) (*Result, error) {
+		record(cmd)
+		return &Result{}, nil
+	}}
+}
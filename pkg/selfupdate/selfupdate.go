@@ -0,0 +1,360 @@
+// Package selfupdate replaces the running executable with the release
+// published on an update server.
+//
+// The server publishes a manifest at <base-url>/manifest.json:
+//
+//	{
+//	  "version": "v1.3.0",
+//	  "assets": {
+//	    "linux/amd64": {
+//	      "url": "tool-linux-amd64",
+//	      "sha256": "<hex checksum of the binary>",
+//	      "signature": "<optional base64 ed25519 signature of the binary>"
+//	    }
+//	  }
+//	}
+//
+// Asset urls are resolved relative to the manifest.
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManifestName is the name of the manifest below the base url.
+const ManifestName = "manifest.json"
+
+// backupSuffix is appended to the executable path to keep the previous binary.
+const backupSuffix = ".old"
+
+// ErrNoBackup is returned by Rollback when there is no previous binary.
+var ErrNoBackup = errors.New("no previous version to roll back to")
+
+// Manifest describes the latest release.
+type Manifest struct {
+	Version string `json:"version"`
+	// Assets are keyed by platform, e.g. linux/amd64.
+	Assets map[string]Asset `json:"assets"`
+}
+
+// Asset is the binary of a release for one platform.
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Release is the asset of the latest release for the platform of the updater.
+type Release struct {
+	Version string
+	Asset   Asset
+	// URL is the absolute url of the asset.
+	URL string
+}
+
+// Updater checks and applies updates of an executable.
+type Updater struct {
+	// BaseURL is the url the manifest is published under.
+	BaseURL string
+	// Current is the version of the running executable.
+	Current string
+	// Platform selects the asset of the manifest, e.g. linux/amd64.
+	Platform string
+	// Executable is the path of the binary to replace.
+	Executable string
+	// PublicKey verifies the signature of the assets when set, unsigned assets
+	// are rejected then.
+	PublicKey ed25519.PublicKey
+	// Client is used for the requests, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Check fetches the manifest and returns the release for the platform and
+// whether it is newer than the current version.
+func (u *Updater) Check(ctx context.Context) (*Release, bool, error) {
+	base, err := url.Parse(strings.TrimSuffix(u.BaseURL, "/") + "/")
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid base url %q: %v", u.BaseURL, err)
+	}
+	manifestURL := base.ResolveReference(&url.URL{Path: ManifestName})
+
+	data, err := u.get(ctx, manifestURL.String())
+	if err != nil {
+		return nil, false, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false, fmt.Errorf("invalid manifest %s: %v", manifestURL, err)
+	}
+
+	asset, ok := manifest.Assets[u.Platform]
+	if !ok {
+		return nil, false, fmt.Errorf("release %s has no binary for %s", manifest.Version, u.Platform)
+	}
+	assetURL, err := manifestURL.Parse(asset.URL)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid asset url %q: %v", asset.URL, err)
+	}
+
+	release := &Release{Version: manifest.Version, Asset: asset, URL: assetURL.String()}
+	return release, CompareVersions(manifest.Version, u.Current) > 0, nil
+}
+
+// Apply downloads and verifies the binary of release, then swaps it with the
+// executable. The previous binary is kept for Rollback.
+func (u *Updater) Apply(ctx context.Context, release *Release) error {
+	data, err := u.get(ctx, release.URL)
+	if err != nil {
+		return err
+	}
+	if err := u.verify(data, release.Asset); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(u.Executable)
+	if err != nil {
+		return err
+	}
+	// the new binary is written next to the executable so that the renames
+	// stay on the same file system and are atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(u.Executable), "."+filepath.Base(u.Executable)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	return swap(u.Executable, tmp.Name(), u.Executable+backupSuffix)
+}
+
+// Rollback restores the binary replaced by the last Apply, the replacing binary
+// is kept in turn so that a rollback can be undone.
+func (u *Updater) Rollback() error {
+	backup := u.Executable + backupSuffix
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		return ErrNoBackup
+	}
+
+	tmp := u.Executable + ".rollback"
+	if err := os.Rename(backup, tmp); err != nil {
+		return err
+	}
+	if err := swap(u.Executable, tmp, backup); err != nil {
+		_ = os.Rename(tmp, backup)
+		return err
+	}
+	return nil
+}
+
+// swap moves exe to backup and next to exe, exe is restored if the second
+// rename fails.
+func swap(exe, next, backup string) error {
+	_ = os.Remove(backup)
+	if err := os.Rename(exe, backup); err != nil {
+		return fmt.Errorf("failed to back up %s: %v", exe, err)
+	}
+	if err := os.Rename(next, exe); err != nil {
+		_ = os.Rename(backup, exe)
+		return fmt.Errorf("failed to replace %s: %v", exe, err)
+	}
+	return nil
+}
+
+// verify checks the checksum and the signature of data.
+func (u *Updater) verify(data []byte, asset Asset) error {
+	want, err := hex.DecodeString(asset.SHA256)
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("invalid sha256 checksum %q", asset.SHA256)
+	}
+	if got := sha256.Sum256(data); !bytes.Equal(got[:], want) {
+		return fmt.Errorf("checksum mismatch: got %x, want %s", got, asset.SHA256)
+	}
+
+	if u.PublicKey == nil {
+		return nil
+	}
+	if asset.Signature == "" {
+		return errors.New("release is not signed")
+	}
+	signature, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if !ed25519.Verify(u.PublicKey, data, signature) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+func (u *Updater) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: got %d bytes, want %d", len(key), ed25519.PublicKeySize)
+	}
+	return key, nil
+}
+
+// CompareVersions compares two semantic versions like v1.2.3 or 1.2.3-rc.1 and
+// returns -1, 0 or 1. Versions which cannot be parsed, e.g. unknown, are lower
+// than any valid version.
+func CompareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if va.numbers[i] != vb.numbers[i] {
+			if va.numbers[i] < vb.numbers[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a pre-release is lower than the release.
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	}
+	return comparePrerelease(va.pre, vb.pre)
+}
+
+// comparePrerelease compares the dot separated identifiers of two pre-release
+// versions as semver §11 does: numeric identifiers are compared as numbers and
+// are lower than alphanumeric ones, which are compared in ASCII order, and a
+// pre-release with more identifiers is higher when the others are equal, e.g.
+// rc.9 < rc.10 < rc.10.1 < rc.beta.
+func comparePrerelease(a, b string) int {
+	ia, ib := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(ia) && i < len(ib); i++ {
+		if c := compareIdentifier(ia[i], ib[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(ia) < len(ib):
+		return -1
+	case len(ia) > len(ib):
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	na, nb := isNumeric(a), isNumeric(b)
+	switch {
+	case na && nb:
+		// numeric identifiers have no leading zeros, the longer one is higher.
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+	case na:
+		return -1
+	case nb:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type semver struct {
+	numbers [3]int
+	pre     string
+}
+
+func parseVersion(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, v.pre = s[:i], s[i+1:]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,160 @@
+package selfupdate_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/selfupdate"
+	"github.com/stretchr/testify/assert"
+)
+
+var newBinary = []byte("#!/bin/sh\necho v1.3.0\n")
+
+func newServer(t *testing.T, asset selfupdate.Asset) *httptest.Server {
+	manifest, err := json.Marshal(selfupdate.Manifest{
+		Version: "v1.3.0",
+		Assets:  map[string]selfupdate.Asset{"linux/amd64": asset},
+	})
+	assert.Nil(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(manifest)
+	})
+	mux.HandleFunc("/releases/tool-linux-amd64", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(newBinary)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newUpdater(t *testing.T, server *httptest.Server, current string) *selfupdate.Updater {
+	exe := filepath.Join(t.TempDir(), "tool")
+	assert.Nil(t, os.WriteFile(exe, []byte("old"), 0o755))
+	return &selfupdate.Updater{
+		BaseURL:    server.URL + "/releases",
+		Current:    current,
+		Platform:   "linux/amd64",
+		Executable: exe,
+		Client:     server.Client(),
+	}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_UpdateAndRollback(t *testing.T) {
+	server := newServer(t, selfupdate.Asset{URL: "tool-linux-amd64", SHA256: checksum(newBinary)})
+	updater := newUpdater(t, server, "v1.2.0")
+
+	release, newer, err := updater.Check(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, newer)
+	assert.Equal(t, "v1.3.0", release.Version)
+	assert.Equal(t, server.URL+"/releases/tool-linux-amd64", release.URL)
+
+	assert.Nil(t, updater.Apply(context.Background(), release))
+	data, _ := os.ReadFile(updater.Executable)
+	assert.Equal(t, newBinary, data)
+	backup, _ := os.ReadFile(updater.Executable + ".old")
+	assert.Equal(t, "old", string(backup))
+	info, err := os.Stat(updater.Executable)
+	assert.Nil(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100)
+
+	assert.Nil(t, updater.Rollback())
+	data, _ = os.ReadFile(updater.Executable)
+	assert.Equal(t, "old", string(data))
+	backup, _ = os.ReadFile(updater.Executable + ".old")
+	assert.Equal(t, newBinary, backup)
+}
+
+func Test_UpToDate(t *testing.T) {
+	server := newServer(t, selfupdate.Asset{URL: "tool-linux-amd64", SHA256: checksum(newBinary)})
+	_, newer, err := newUpdater(t, server, "v1.3.0").Check(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, newer)
+}
+
+func Test_ChecksumMismatch(t *testing.T) {
+	server := newServer(t, selfupdate.Asset{URL: "tool-linux-amd64", SHA256: checksum([]byte("other"))})
+	updater := newUpdater(t, server, "v1.2.0")
+
+	release, _, err := updater.Check(context.Background())
+	assert.Nil(t, err)
+	assert.ErrorContains(t, updater.Apply(context.Background(), release), "checksum mismatch")
+	data, _ := os.ReadFile(updater.Executable)
+	assert.Equal(t, "old", string(data))
+	assert.ErrorIs(t, updater.Rollback(), selfupdate.ErrNoBackup)
+}
+
+func Test_Signature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, newBinary))
+	key, err := selfupdate.ParsePublicKey(base64.StdEncoding.EncodeToString(public))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		signature string
+		err       string
+	}{
+		{name: "signed", signature: signature},
+		{name: "unsigned", err: "not signed"},
+		{name: "bad signature", signature: base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte("other"))), err: "verification failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, selfupdate.Asset{URL: "tool-linux-amd64", SHA256: checksum(newBinary), Signature: tt.signature})
+			updater := newUpdater(t, server, "v1.2.0")
+			updater.PublicKey = key
+
+			release, _, err := updater.Check(context.Background())
+			assert.Nil(t, err)
+			err = updater.Apply(context.Background(), release)
+			if tt.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_CompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.0", "v1.2.0", 0},
+		{"v1.3.0", "v1.2.9", 1},
+		{"1.2", "v1.2.0", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0-rc.1", "v2.0.0", -1},
+		{"v2.0.0-rc.2", "v2.0.0-rc.1", 1},
+		{"v1.0.0-rc.10", "v1.0.0-rc.9", 1},
+		{"v1.0.0-rc.9", "v1.0.0-rc.10", -1},
+		{"v1.0.0-rc.1", "v1.0.0-rc.1.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-beta", "v1.0.0-alpha", 1},
+		{"v0.0.1", "unknown", 1},
+		{"unknown", "unknown", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, selfupdate.CompareVersions(tt.a, tt.b), "%s %s", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,108 @@
+// Package version holds the build information of the binary. The values are
+// injected at build time, e.g.:
+//
+//	go build -ldflags "-X github.com/lwm-galactic/utool/pkg/version.GitVersion=v1.2.0 \
+//	  -X github.com/lwm-galactic/utool/pkg/version.GitCommit=$(git rev-parse HEAD) \
+//	  -X github.com/lwm-galactic/utool/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values which are not injected fall back to runtime/debug.ReadBuildInfo.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+const unknown = "unknown"
+
+var (
+	// GitVersion is the semantic version of the build.
+	GitVersion = ""
+	// GitCommit is the sha1 of the git commit of the build.
+	GitCommit = ""
+	// GitTreeState is clean or dirty depending on the state of the git tree.
+	GitTreeState = ""
+	// BuildDate is the build date in ISO8601 format.
+	BuildDate = ""
+)
+
+// Info contains the version information of the binary.
+type Info struct {
+	GitVersion   string `json:"gitVersion"   yaml:"gitVersion"`
+	GitCommit    string `json:"gitCommit"    yaml:"gitCommit"`
+	GitTreeState string `json:"gitTreeState" yaml:"gitTreeState"`
+	BuildDate    string `json:"buildDate"    yaml:"buildDate"`
+	GoVersion    string `json:"goVersion"    yaml:"goVersion"`
+	Compiler     string `json:"compiler"     yaml:"compiler"`
+	Platform     string `json:"platform"     yaml:"platform"`
+}
+
+// Get returns the version information of the binary.
+func Get() Info {
+	info := Info{
+		GitVersion:   GitVersion,
+		GitCommit:    GitCommit,
+		GitTreeState: GitTreeState,
+		BuildDate:    BuildDate,
+		GoVersion:    runtime.Version(),
+		Compiler:     runtime.Compiler,
+		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.fromBuildInfo(bi)
+	}
+	for _, field := range []*string{&info.GitVersion, &info.GitCommit, &info.GitTreeState, &info.BuildDate} {
+		if *field == "" {
+			*field = unknown
+		}
+	}
+
+	return info
+}
+
+// fromBuildInfo fills the fields which are not injected by ldflags.
+func (info *Info) fromBuildInfo(bi *debug.BuildInfo) {
+	if info.GitVersion == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.GitVersion = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.GitCommit == "" {
+				info.GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			if info.GitTreeState == "" {
+				info.GitTreeState = "clean"
+				if setting.Value == "true" {
+					info.GitTreeState = "dirty"
+				}
+			}
+		}
+	}
+}
+
+// String returns the semantic version.
+func (info Info) String() string {
+	return info.GitVersion
+}
+
+// Text returns the version information as aligned lines.
+func (info Info) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GitVersion:    %s\n", info.GitVersion)
+	fmt.Fprintf(&b, "GitCommit:     %s\n", info.GitCommit)
+	fmt.Fprintf(&b, "GitTreeState:  %s\n", info.GitTreeState)
+	fmt.Fprintf(&b, "BuildDate:     %s\n", info.BuildDate)
+	fmt.Fprintf(&b, "GoVersion:     %s\n", info.GoVersion)
+	fmt.Fprintf(&b, "Compiler:      %s\n", info.Compiler)
+	fmt.Fprintf(&b, "Platform:      %s\n", info.Platform)
+	return b.String()
+}
@@ -0,0 +1,21 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/lwm-galactic/utool/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Get(t *testing.T) {
+	defer func(v, c string) { version.GitVersion, version.GitCommit = v, c }(version.GitVersion, version.GitCommit)
+	version.GitVersion, version.GitCommit = "v1.2.0", "abc123"
+
+	info := version.Get()
+	assert.Equal(t, "v1.2.0", info.GitVersion)
+	assert.Equal(t, "abc123", info.GitCommit)
+	assert.NotEmpty(t, info.BuildDate)
+	assert.NotEmpty(t, info.GoVersion)
+	assert.NotEmpty(t, info.Platform)
+	assert.Equal(t, "v1.2.0", info.String())
+}